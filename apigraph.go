@@ -0,0 +1,184 @@
+package kraph
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/milosgajdos/kraph/pkg/api"
+	"github.com/milosgajdos/kraph/pkg/api/gen"
+	"github.com/milosgajdos/kraph/pkg/store"
+	"github.com/milosgajdos/kraph/pkg/uuid"
+)
+
+const (
+	// APIGroup is the API group of API graph objects
+	APIGroup = "kraph"
+	// APIVersion is the API version of API graph objects
+	APIVersion = "v1"
+	// GroupKind is the kind of API group objects
+	GroupKind = "Group"
+	// VersionKind is the kind of API version objects
+	VersionKind = "Version"
+	// ResourceKind is the kind of API resource objects
+	ResourceKind = "Resource"
+	// HasVersionRel links API groups to their versions
+	HasVersionRel = "hasVersion"
+	// HasResourceRel links API versions to their resources
+	HasResourceRel = "hasResource"
+	// InstanceOfRel links API objects to their resources
+	InstanceOfRel = "instanceOf"
+	// coreGroup is the name of the API group with empty name
+	coreGroup = "core"
+)
+
+// apiGraph maps API groups, versions and resources into API objects
+type apiGraph struct {
+	// src is API source
+	src api.Source
+	// prefix prefixes API graph object UIDs
+	prefix string
+	// top contains API graph objects
+	top *gen.Top
+	// objects indexes API graph objects by their UIDs
+	objects map[string]*gen.Object
+	// resources are API graph object resources
+	resources map[string]api.Resource
+}
+
+// newAPIGraph returns API graph of API a whose objects are in top.
+// API graph objects are scoped to the API source if the API objects are.
+func newAPIGraph(a api.API, top api.Top) *apiGraph {
+	var src api.Source
+	for _, obj := range top.Objects() {
+		if obj.Source() != nil {
+			src = a.Source()
+			break
+		}
+	}
+
+	prefix := "api"
+	if a.Source() != nil {
+		prefix += "/" + a.Source().String()
+	}
+
+	g := &apiGraph{
+		src:     src,
+		prefix:  prefix,
+		top:     gen.NewTop(),
+		objects: make(map[string]*gen.Object),
+		resources: map[string]api.Resource{
+			GroupKind:    gen.NewResource("groups", GroupKind, APIGroup, APIVersion, false),
+			VersionKind:  gen.NewResource("versions", VersionKind, APIGroup, APIVersion, false),
+			ResourceKind: gen.NewResource("resources", ResourceKind, APIGroup, APIVersion, false),
+		},
+	}
+
+	for _, res := range a.Resources() {
+		g.resource(res)
+	}
+
+	return g
+}
+
+// object returns API graph object of the given kind and name.
+// The object is created and linked to its parent object if it does not exist.
+func (g *apiGraph) object(kind, name string, fields map[string]string, parent *gen.Object, rel string) *gen.Object {
+	uid := g.prefix + "/" + name
+	if obj, ok := g.objects[uid]; ok {
+		return obj
+	}
+
+	obj := gen.NewObject(uuid.NewFromString(uid), name, api.NsGlobal, g.resources[kind],
+		gen.Fields(fields),
+		gen.FromSource(g.src),
+	)
+
+	if parent != nil {
+		parent.Link(obj.UID(), gen.NewRelation(rel))
+	}
+
+	g.objects[uid] = obj
+	g.top.Add(obj)
+
+	return obj
+}
+
+// resource returns API graph object of API resource res
+func (g *apiGraph) resource(res api.Resource) *gen.Object {
+	group := res.Group()
+	if len(group) == 0 {
+		group = coreGroup
+	}
+
+	groupObj := g.object(GroupKind, group, nil, nil, "")
+	versionFields := map[string]string{
+		"preferred": strconv.FormatBool(res.Version() == res.PreferredVersion()),
+	}
+	versionObj := g.object(VersionKind, group+"/"+res.Version(), versionFields, groupObj, HasVersionRel)
+
+	fields := map[string]string{
+		"kind":       res.Kind(),
+		"namespaced": strconv.FormatBool(res.Namespaced()),
+	}
+
+	if len(res.Verbs()) > 0 {
+		fields["verbs"] = strings.Join(res.Verbs(), ",")
+	}
+
+	if len(res.ShortNames()) > 0 {
+		fields["shortNames"] = strings.Join(res.ShortNames(), ",")
+	}
+
+	if len(res.Categories()) > 0 {
+		fields["categories"] = strings.Join(res.Categories(), ",")
+	}
+
+	if res.Deprecated() {
+		fields["deprecated"] = "true"
+	}
+
+	name := strings.Join([]string{group, res.Version(), res.Name()}, "/")
+
+	return g.object(ResourceKind, name, fields, versionObj, HasResourceRel)
+}
+
+// buildAPIGraph adds the groups, versions and resources of API a to the graph
+// in transaction tx and links the objects in top which pass the filters to their resources.
+// API graph objects are never filtered.
+func (k *kraph) buildAPIGraph(tx store.Tx, a api.API, top api.Top, filters ...Filter) error {
+	g := newAPIGraph(a, top)
+
+	var rels []Relationship
+
+	for _, object := range top.Objects() {
+		if skipGraph(object, filters...) || object.Resource() == nil {
+			continue
+		}
+
+		rels = append(rels, Relationship{
+			From:     object,
+			To:       g.resource(object.Resource()),
+			Relation: gen.NewRelation(InstanceOfRel),
+		})
+	}
+
+	for _, object := range g.top.Objects() {
+		if _, err := tx.Add(object, store.AddOptions{}); err != nil {
+			return fmt.Errorf("error adding node: %w", err)
+		}
+	}
+
+	apiRels, err := ObjectLinker{}.Link(g.top)
+	if err != nil {
+		return err
+	}
+
+	for _, rel := range append(apiRels, rels...) {
+		if err := k.link(tx, rel); err != nil {
+			return fmt.Errorf("error linking objects: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,136 @@
+package kraph
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/milosgajdos/kraph/pkg/api"
+	"github.com/milosgajdos/kraph/pkg/api/gen"
+	"github.com/milosgajdos/kraph/pkg/query"
+	"github.com/milosgajdos/kraph/pkg/store"
+	"github.com/milosgajdos/kraph/pkg/store/memory"
+)
+
+func TestBuildAPIGraph(t *testing.T) {
+	client, err := gen.NewMockClient(resPath, objPath)
+	if err != nil {
+		t.Fatalf("failed to build mock client: %v", err)
+	}
+
+	a, err := client.Discover()
+	if err != nil {
+		t.Fatalf("failed to discover API: %v", err)
+	}
+
+	groups := make(map[string]bool)
+	for _, res := range a.Resources() {
+		groups[res.Group()] = true
+	}
+
+	m, err := memory.NewStore("memory", store.Options{})
+	if err != nil {
+		t.Fatalf("failed to create memory store: %v", err)
+	}
+
+	k, err := New(Store(m), APIGraph(true))
+	if err != nil {
+		t.Fatalf("failed to create kraph: %v", err)
+	}
+
+	if _, err := k.Build(client, KindFilter("fooKind")); err != nil {
+		t.Fatalf("failed to build graph: %v", err)
+	}
+
+	nodes, err := m.Query(query.Build().Entity(query.Node).Kind(GroupKind))
+	if err != nil {
+		t.Fatalf("failed to query groups: %v", err)
+	}
+
+	if len(nodes) != len(groups) {
+		t.Errorf("expected groups: %d, got: %d", len(groups), len(nodes))
+	}
+
+	// traverse fooGroup objects: group -> versions -> resources -> objects
+	var group store.Node
+	for _, n := range nodes {
+		obj := n.(store.Node).Metadata().Get("object").(api.Object)
+		if obj.Name() == "fooGroup" {
+			group = n.(store.Node)
+		}
+	}
+
+	if group == nil {
+		t.Fatalf("group fooGroup not found")
+	}
+
+	versions, err := m.Neighbours(group, HasVersionRel)
+	if err != nil {
+		t.Fatalf("failed to get versions: %v", err)
+	}
+
+	if len(versions) != 2 {
+		t.Errorf("expected versions: %d, got: %d", 2, len(versions))
+	}
+
+	count := 0
+
+	for _, version := range versions {
+		v := version.Metadata().Get("object").(api.Object)
+		if preferred := v.Name() == "fooGroup/v2"; v.Fields()["preferred"] != strconv.FormatBool(preferred) {
+			t.Errorf("%s: expected preferred: %v, got: %s", v.Name(), preferred, v.Fields()["preferred"])
+		}
+
+		resources, err := m.Neighbours(version, HasResourceRel)
+		if err != nil {
+			t.Fatalf("failed to get resources: %v", err)
+		}
+
+		for _, res := range resources {
+			obj := res.Metadata().Get("object").(api.Object)
+			if obj.Fields()["kind"] != "fooKind" || obj.Fields()["namespaced"] != "true" {
+				t.Errorf("unexpected resource fields: %v", obj.Fields())
+			}
+
+			if obj.Fields()["categories"] != "all" {
+				t.Errorf("expected categories: %s, got: %s", "all", obj.Fields()["categories"])
+			}
+
+			if deprecated := v.Name() == "fooGroup/v1"; (obj.Fields()["deprecated"] == "true") != deprecated {
+				t.Errorf("%s: expected deprecated: %v, got: %v", obj.Name(), deprecated, obj.Fields())
+			}
+
+			objects, err := m.Neighbours(res, InstanceOfRel)
+			if err != nil {
+				t.Fatalf("failed to get objects: %v", err)
+			}
+
+			for _, o := range objects {
+				if g := o.Metadata().Get("object").(api.Object).Resource().Group(); g != "fooGroup" {
+					t.Errorf("expected group: %s, got: %s", "fooGroup", g)
+				}
+				count++
+			}
+		}
+	}
+
+	if count == 0 {
+		t.Errorf("no fooGroup objects found")
+	}
+
+	// filtered out objects are not linked to their resources
+	barNodes, err := m.Query(query.Build().Entity(query.Node).Kind("barKind"))
+	if err != nil {
+		t.Fatalf("failed to query nodes: %v", err)
+	}
+
+	for _, n := range barNodes {
+		resources, err := m.Neighbours(n.(store.Node), InstanceOfRel)
+		if err != nil {
+			t.Fatalf("failed to get resources: %v", err)
+		}
+
+		if len(resources) != 0 {
+			t.Errorf("expected no resources of filtered out object %s", n.(store.Node).UID())
+		}
+	}
+}
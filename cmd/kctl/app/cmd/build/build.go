@@ -1,6 +1,13 @@
 package build
 
 import (
+	"fmt"
+	"os"
+
+	"github.com/milosgajdos/kraph"
+	"github.com/milosgajdos/kraph/pkg/api"
+	"github.com/milosgajdos/kraph/pkg/store"
+	"github.com/milosgajdos/kraph/pkg/store/memory"
 	"github.com/urfave/cli/v2"
 )
 
@@ -12,7 +19,28 @@ func New() *cli.Command {
 		Subcommands: []*cli.Command{},
 	}
 
-	build.Subcommands = append(build.Subcommands, K8s())
+	build.Subcommands = append(build.Subcommands, K8s(), Compose(), Terraform(), Helm(), OpenAPI(), Inventory(), GoMod())
 
 	return build
 }
+
+// runClient builds the graph of the API client in memory store
+// and writes it to standard output in the format given by the format flag.
+func runClient(client api.Client) error {
+	gstore, err := memory.NewStore("kctl", store.Options{})
+	if err != nil {
+		return err
+	}
+
+	k, err := kraph.New(kraph.Store(gstore))
+	if err != nil {
+		return fmt.Errorf("failed to create kraph: %w", err)
+	}
+
+	g, err := k.Build(client)
+	if err != nil {
+		return fmt.Errorf("failed to build kraph: %w", err)
+	}
+
+	return writeGraph(os.Stdout, g, format)
+}
@@ -0,0 +1,54 @@
+package build
+
+import (
+	"github.com/milosgajdos/kraph/pkg/api/compose"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	composeFile string
+	project     string
+)
+
+// Compose returns Docker Compose subcommand for build command
+func Compose() *cli.Command {
+	return &cli.Command{
+		Name:     "compose",
+		Category: "build",
+		Usage:    "docker compose graph",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:        "file",
+				Aliases:     []string{"F"},
+				Value:       "docker-compose.yaml",
+				Usage:       "Path to a compose file",
+				Destination: &composeFile,
+			},
+			&cli.StringFlag{
+				Name:        "project",
+				Aliases:     []string{"p"},
+				Usage:       "compose project name (defaults to the compose file directory name)",
+				Destination: &project,
+			},
+			&cli.StringFlag{
+				Name:        "format",
+				Aliases:     []string{"f"},
+				Value:       "dot",
+				Usage:       "print graph in a given format: dot, nodes, edges",
+				Destination: &format,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			return runCompose(c)
+		},
+	}
+}
+
+func runCompose(ctx *cli.Context) error {
+	var opts []compose.Option
+	if len(project) > 0 {
+		opts = append(opts, compose.Project(project))
+	}
+
+	return runClient(compose.NewClient(composeFile, opts...))
+}
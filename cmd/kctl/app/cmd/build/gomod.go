@@ -0,0 +1,37 @@
+package build
+
+import (
+	"github.com/milosgajdos/kraph/pkg/api/gomod"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	modulePaths cli.StringSlice
+)
+
+// GoMod returns Go modules subcommand for build command
+func GoMod() *cli.Command {
+	return &cli.Command{
+		Name:     "gomod",
+		Category: "build",
+		Usage:    "go module dependency graph",
+		Flags: []cli.Flag{
+			&cli.StringSliceFlag{
+				Name:        "path",
+				Usage:       "Path to a go.mod, go.sum or go list -m -json output file or a directory with go.mod files (repeatable)",
+				Required:    true,
+				Destination: &modulePaths,
+			},
+			&cli.StringFlag{
+				Name:        "format",
+				Aliases:     []string{"f"},
+				Value:       "dot",
+				Usage:       "print graph in a given format: dot, nodes, edges",
+				Destination: &format,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			return runClient(gomod.NewClient(modulePaths.Value()))
+		},
+	}
+}
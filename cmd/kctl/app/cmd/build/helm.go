@@ -0,0 +1,45 @@
+package build
+
+import (
+	"github.com/milosgajdos/kraph/pkg/api/helm"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	releasePaths cli.StringSlice
+	releaseNs    string
+)
+
+// Helm returns Helm subcommand for build command
+func Helm() *cli.Command {
+	return &cli.Command{
+		Name:     "helm",
+		Category: "build",
+		Usage:    "helm releases graph",
+		Flags: []cli.Flag{
+			&cli.StringSliceFlag{
+				Name:        "path",
+				Usage:       "Path to a file or directory with helm releases or rendered manifests (repeatable)",
+				Required:    true,
+				Destination: &releasePaths,
+			},
+			&cli.StringFlag{
+				Name:        "namespace",
+				Aliases:     []string{"ns"},
+				Value:       helm.DefaultNamespace,
+				Usage:       "namespace of the releases rendered by helm template",
+				Destination: &releaseNs,
+			},
+			&cli.StringFlag{
+				Name:        "format",
+				Aliases:     []string{"f"},
+				Value:       "dot",
+				Usage:       "print graph in a given format: dot, nodes, edges",
+				Destination: &format,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			return runClient(helm.NewClient(releasePaths.Value(), helm.Namespace(releaseNs)))
+		},
+	}
+}
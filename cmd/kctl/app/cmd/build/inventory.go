@@ -0,0 +1,51 @@
+package build
+
+import (
+	"fmt"
+
+	"github.com/milosgajdos/kraph/pkg/api/inventory"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	mappingFile    string
+	inventoryPaths cli.StringSlice
+)
+
+// Inventory returns inventory subcommand for build command
+func Inventory() *cli.Command {
+	return &cli.Command{
+		Name:     "inventory",
+		Category: "build",
+		Usage:    "graph of objects mapped from JSON or YAML files",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:        "mapping",
+				Usage:       "Path to a mapping rules YAML file",
+				Required:    true,
+				Destination: &mappingFile,
+			},
+			&cli.StringSliceFlag{
+				Name:        "path",
+				Usage:       "Path to a JSON or YAML file or directory with mapped objects (repeatable)",
+				Required:    true,
+				Destination: &inventoryPaths,
+			},
+			&cli.StringFlag{
+				Name:        "format",
+				Aliases:     []string{"f"},
+				Value:       "dot",
+				Usage:       "print graph in a given format: dot, nodes, edges",
+				Destination: &format,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			m, err := inventory.LoadMapping(mappingFile)
+			if err != nil {
+				return fmt.Errorf("failed to load mapping: %w", err)
+			}
+
+			return runClient(inventory.NewClient(m, inventoryPaths.Value()))
+		},
+	}
+}
@@ -1,15 +1,21 @@
 package build
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
 
-	"github.com/milosgajdos/kraph/pkg/api"
-
 	"github.com/milosgajdos/kraph"
+	"github.com/milosgajdos/kraph/pkg/api"
+	"github.com/milosgajdos/kraph/pkg/api/gen"
 	"github.com/milosgajdos/kraph/pkg/api/k8s"
+	"github.com/milosgajdos/kraph/pkg/api/openapi"
+	kerrors "github.com/milosgajdos/kraph/pkg/errors"
+	"github.com/milosgajdos/kraph/pkg/query"
+	"github.com/milosgajdos/kraph/pkg/relation"
 	"github.com/milosgajdos/kraph/pkg/store"
 	"github.com/milosgajdos/kraph/pkg/store/memory"
 	"github.com/urfave/cli/v2"
@@ -17,17 +23,30 @@ import (
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
 	"k8s.io/client-go/util/homedir"
 )
 
 var (
-	kinds      string
-	kubeconfig string
-	master     string
-	namespace  string
-	format     string
-	graphStore string
-	storeURL   string
+	kinds        string
+	excludeKinds string
+	excludeNs    string
+	filterLinks  bool
+	relations    string
+	fields       cli.StringSlice
+	kubeconfig   string
+	contexts     string
+	master       string
+	namespace    string
+	format       string
+	graphStore   string
+	storeURL     string
+	root         string
+	depth        int
+	openapiRefs  bool
+	apiGraph     bool
+	allVersions  bool
+	subresources bool
 )
 
 // K8s returns K8s subcommand for build command
@@ -41,10 +60,55 @@ func K8s() *cli.Command {
 			&cli.StringFlag{
 				Name:        "kinds",
 				Aliases:     []string{"k"},
-				Value:       "all",
-				Usage:       "filter by resource kinds (comma separated)",
+				Usage:       "filter by resource names, short names, kinds or categories, e.g. po,deploy,ingresses.networking.k8s.io (comma separated)",
 				Destination: &kinds,
 			},
+			&cli.StringFlag{
+				Name:        "exclude-kinds",
+				Usage:       "exclude resource names, short names, kinds or categories (comma separated)",
+				Destination: &excludeKinds,
+			},
+			&cli.StringFlag{
+				Name:        "exclude-namespaces",
+				Usage:       "exclude namespaces (comma separated)",
+				Destination: &excludeNs,
+			},
+			&cli.BoolFlag{
+				Name:        "filter-links",
+				Usage:       "do not link objects to filtered out objects",
+				Destination: &filterLinks,
+			},
+			&cli.StringFlag{
+				Name:        "relations",
+				Aliases:     []string{"r"},
+				Usage:       "Path to a relations YAML file",
+				Destination: &relations,
+			},
+			&cli.StringSliceFlag{
+				Name:        "field",
+				Usage:       "extract object field into node attributes: name=jsonpath (repeatable)",
+				Destination: &fields,
+			},
+			&cli.BoolFlag{
+				Name:        "openapi-refs",
+				Usage:       "link objects by the reference fields found in the OpenAPI schema of each cluster",
+				Destination: &openapiRefs,
+			},
+			&cli.BoolFlag{
+				Name:        "api-graph",
+				Usage:       "add API groups, versions and resources to the graph",
+				Destination: &apiGraph,
+			},
+			&cli.BoolFlag{
+				Name:        "all-versions",
+				Usage:       "discover all served API versions instead of the preferred ones",
+				Destination: &allVersions,
+			},
+			&cli.BoolFlag{
+				Name:        "subresources",
+				Usage:       "add object subresources, such as pods/log, to the graph",
+				Destination: &subresources,
+			},
 			&cli.StringFlag{
 				Name:        "store",
 				Aliases:     []string{"s"},
@@ -66,6 +130,11 @@ func K8s() *cli.Command {
 				Usage:       "Path to a kubeconfig",
 				Destination: &kubeconfig,
 			},
+			&cli.StringFlag{
+				Name:        "contexts",
+				Usage:       "kubeconfig contexts of the clusters merged into the graph (comma separated)",
+				Destination: &contexts,
+			},
 			&cli.StringFlag{
 				Name:        "master",
 				Aliases:     []string{"m"},
@@ -78,11 +147,22 @@ func K8s() *cli.Command {
 				Usage:       "Kubernetes namespace",
 				Destination: &namespace,
 			},
+			&cli.StringFlag{
+				Name:        "root",
+				Usage:       "render only the neighbourhood of the object: [cluster/]kind/namespace/name",
+				Destination: &root,
+			},
+			&cli.IntFlag{
+				Name:        "depth",
+				Value:       1,
+				Usage:       "maximum distance of the rendered objects from the root object",
+				Destination: &depth,
+			},
 			&cli.StringFlag{
 				Name:        "format",
 				Aliases:     []string{"f"},
 				Value:       "dot",
-				Usage:       "print graph in a given format",
+				Usage:       "print graph in a given format: dot, nodes, edges",
 				Destination: &format,
 			},
 		},
@@ -94,11 +174,13 @@ func K8s() *cli.Command {
 
 // getKubeConfig builds kubernetes configuration and returns it.
 // It looks for kubernetes config file in the following order:
-// 	1. kubeconfig
-// 	2. $KUBECONFIG environment variable
-// 	3. $HOMEDIR/.kube/config
+//  1. kubeconfig
+//  2. $KUBECONFIG environment variable
+//  3. $HOMEDIR/.kube/config
+//
+// If context is not empty the configuration of the given kubeconfig context is built.
 // It returns error if the configuration could not be built.
-func getKubeConfig(masterURL, kubeconfig string) (*rest.Config, error) {
+func getKubeConfig(masterURL, kubeconfig, context string) (*rest.Config, error) {
 	if kubeconfig == "" {
 		kubeconfig = os.Getenv("KUBECONFIG")
 		if kubeconfig == "" {
@@ -108,30 +190,35 @@ func getKubeConfig(masterURL, kubeconfig string) (*rest.Config, error) {
 		}
 	}
 
-	// NOTE: if neither masterURL nor kubeconfig is provided this defaults to in-cluster config
-	config, err := clientcmd.BuildConfigFromFlags(masterURL, kubeconfig)
+	if context == "" {
+		// NOTE: if neither masterURL nor kubeconfig is provided this defaults to in-cluster config
+		config, err := clientcmd.BuildConfigFromFlags(masterURL, kubeconfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed building kubernetes config: %v", err)
+		}
+
+		return config, nil
+	}
+
+	config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		&clientcmd.ClientConfigLoadingRules{ExplicitPath: kubeconfig},
+		&clientcmd.ConfigOverrides{
+			CurrentContext: context,
+			ClusterInfo:    clientcmdapi.Cluster{Server: masterURL},
+		}).ClientConfig()
 	if err != nil {
-		return nil, fmt.Errorf("failed building kubernetes config: %v", err)
+		return nil, fmt.Errorf("failed building kubernetes config for context %s: %v", context, err)
 	}
 
 	return config, nil
 }
 
-func graphToOut(g store.Graph, format string) (string, error) {
-	switch format {
-	case "dot":
-		dotGraph := g.(store.DOTGraph)
-		return dotGraph.DOT()
-	default:
-		dotGraph := g.(store.DOTGraph)
-		return dotGraph.DOT()
-	}
-}
-
-func run(ctx *cli.Context) error {
-	config, err := getKubeConfig(master, kubeconfig)
+// newClient creates a new kubernetes API client for the given kubeconfig context.
+// If context is not empty the client objects are scoped to the context cluster.
+func newClient(ctx *cli.Context, context string, opts ...k8s.Option) (api.Client, error) {
+	config, err := getKubeConfig(master, kubeconfig, context)
 	if err != nil {
-		return fmt.Errorf("failed to get kubernetes config: %w", err)
+		return nil, fmt.Errorf("failed to get kubernetes config: %w", err)
 	}
 
 	// adjust configuration for faster scan
@@ -140,59 +227,394 @@ func run(ctx *cli.Context) error {
 
 	discClient, err := kubernetes.NewForConfig(config)
 	if err != nil {
-		return fmt.Errorf("failed to build kubernetes clientset: %w", err)
+		return nil, fmt.Errorf("failed to build kubernetes clientset: %w", err)
 	}
 
 	dynClient, err := dynamic.NewForConfig(config)
 	if err != nil {
-		return fmt.Errorf("failed to build kubernetes dynamic client: %w", err)
+		return nil, fmt.Errorf("failed to build kubernetes dynamic client: %w", err)
+	}
+
+	if context != "" {
+		opts = append(opts, k8s.Cluster(context))
+	}
+
+	return k8s.NewClient(ctx.Context, discClient.Discovery(), dynClient, opts...), nil
+}
+
+// schemaRefs discovers the reference fields in the OpenAPI schema of the cluster
+// of the given kubeconfig context. It returns the JSONPath expressions which
+// extract the fields from API objects and the field references which link
+// the objects of the cluster to the objects referenced by the fields.
+func schemaRefs(context string) (map[string]string, []kraph.FieldRef, error) {
+	config, err := getKubeConfig(master, kubeconfig, context)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get kubernetes config: %w", err)
+	}
+
+	discClient, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to build kubernetes clientset: %w", err)
+	}
+
+	refs, err := openapi.NewClient(openapi.DiscoveryLoader(discClient.Discovery())).References()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to discover schema references: %w", err)
 	}
 
+	paths := make(map[string]string)
+	var fieldRefs []kraph.FieldRef
+
+	for _, ref := range refs {
+		paths[ref.Path] = ref.Path
+		fieldRefs = append(fieldRefs, kraph.FieldRef{
+			Kind:     ref.Kind,
+			Field:    ref.Path,
+			To:       ref.To,
+			Relation: gen.NewRelation(ref.Field),
+			Source:   context,
+		})
+	}
+
+	return paths, fieldRefs, nil
+}
+
+// writeGraph writes the graph g to w in the given format.
+// The nodes and edges formats stream the graph one entity per line.
+func writeGraph(w io.Writer, g store.Graph, format string) error {
+	switch format {
+	case "nodes":
+		it, err := g.IterNodes()
+		if err != nil {
+			return fmt.Errorf("failed to query nodes: %w", err)
+		}
+		defer it.Close()
+
+		for it.Next() {
+			if _, err := fmt.Fprintln(w, it.Node().Attrs().Get("name")); err != nil {
+				return err
+			}
+		}
+
+		return it.Err()
+	case "edges":
+		it, err := g.AllEdges()
+		if err != nil {
+			return fmt.Errorf("failed to query edges: %w", err)
+		}
+		defer it.Close()
+
+		for it.Next() {
+			e := it.Edge()
+			if _, err := fmt.Fprintf(w, "%s -> %s [%s]\n",
+				e.From().Attrs().Get("name"),
+				e.To().Attrs().Get("name"),
+				e.Attrs().Get("relation")); err != nil {
+				return err
+			}
+		}
+
+		return it.Err()
+	default:
+		dotGraph := g.(store.DOTGraph)
+		out, err := dotGraph.DOT()
+		if err != nil {
+			return err
+		}
+
+		_, err = fmt.Fprintln(w, out)
+		return err
+	}
+}
+
+// buildFilters builds kraph filters from command line flags and returns them.
+// The resource kinds are resolved in the discovered APIs apis.
+// It returns error if any of the kinds can not be resolved.
+func buildFilters(apis []api.API) ([]kraph.Filter, error) {
+	var filters []kraph.Filter
+
+	if len(kinds) > 0 {
+		resources, err := resolveKinds(apis, strings.Split(kinds, ","))
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, kraph.ResourceFilter(resources...))
+	}
+
+	if len(excludeKinds) > 0 {
+		resources, err := resolveKinds(apis, strings.Split(excludeKinds, ","))
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, kraph.Not(kraph.ResourceFilter(resources...)))
+	}
+
+	if len(excludeNs) > 0 {
+		filters = append(filters, kraph.Not(kraph.NsFilter(strings.Split(excludeNs, ",")...)))
+	}
+
+	if len(filters) == 0 {
+		return nil, nil
+	}
+
+	return []kraph.Filter{kraph.And(filters...)}, nil
+}
+
+// resolveKinds resolves the given names to API resources the way kubectl does.
+// A name is either a resource category, such as all, or a resource name,
+// short name or kind optionally qualified by its group, e.g. ingresses.networking.k8s.io.
+// It returns error if any of the names is not found in any of the APIs.
+func resolveKinds(apis []api.API, names []string) ([]api.Resource, error) {
+	var resources []api.Resource
+
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		found := false
+
+		for _, a := range apis {
+			res, err := lookupKind(a, name)
+			if err != nil {
+				if errors.Is(err, kerrors.ErrUnknownResource) {
+					continue
+				}
+				return nil, fmt.Errorf("failed to resolve %s: %w", name, err)
+			}
+
+			resources = append(resources, res...)
+			found = true
+		}
+
+		if !found {
+			return nil, fmt.Errorf("%w: %s", kerrors.ErrUnknownResource, name)
+		}
+	}
+
+	return resources, nil
+}
+
+// lookupKind returns the resources of API a in the category name.
+// If no resource is in the category it looks the resources up by name.
+func lookupKind(a api.API, name string) ([]api.Resource, error) {
+	var resources []api.Resource
+	for _, res := range a.Resources() {
+		if api.StringIn(name, res.Categories()) {
+			resources = append(resources, res)
+		}
+	}
+
+	if len(resources) > 0 {
+		return resources, nil
+	}
+
+	return a.Lookup(name)
+}
+
+// discovered is API client whose API has already been discovered
+type discovered struct {
+	api.Client
+	api api.API
+}
+
+// Discover returns the discovered API
+func (d discovered) Discover() (api.API, error) {
+	return d.api, nil
+}
+
+// subGraph returns the subgraph of s rooted at the object root
+// given as [cluster/]kind/namespace/name up to the given depth.
+// The root kind is resolved in the discovered APIs apis.
+func subGraph(s store.Store, apis []api.API, root string, depth int) (store.Graph, error) {
+	parts := strings.Split(root, "/")
+	if len(parts) != 3 && len(parts) != 4 {
+		return nil, fmt.Errorf("invalid root: %s", root)
+	}
+
+	var source string
+	if len(parts) == 4 {
+		source = parts[0]
+		parts = parts[1:]
+	}
+
+	resources, err := resolveKinds(apis, parts[:1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid root %s: %w", root, err)
+	}
+
+	var nodes []store.Entity
+	kinds := make(map[string]bool)
+
+	for _, res := range resources {
+		if kinds[res.Kind()] {
+			continue
+		}
+		kinds[res.Kind()] = true
+
+		q := query.Build().Entity(query.Node)
+		if len(source) > 0 {
+			q = q.Source(source)
+		}
+
+		q = q.Kind(res.Kind()).
+			Namespace(strings.ToLower(parts[1])).
+			Name(strings.ToLower(parts[2]))
+
+		ents, err := s.Query(q)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query root: %w", err)
+		}
+		nodes = append(nodes, ents...)
+	}
+
+	if len(nodes) != 1 {
+		return nil, fmt.Errorf("root %s: expected single object, found: %d", root, len(nodes))
+	}
+
+	opts := store.NewSubGraphOptions()
+	opts.Depth = depth
+
+	return s.SubGraph(nodes[0].(store.Node), opts)
+}
+
+// parseFields parses field flags into a map of field names to JSONPath expressions
+func parseFields(fields []string) (map[string]string, error) {
+	paths := make(map[string]string)
+
+	for _, f := range fields {
+		kv := strings.SplitN(f, "=", 2)
+		if len(kv) != 2 || len(kv[0]) == 0 || len(kv[1]) == 0 {
+			return nil, fmt.Errorf("invalid field: %s", f)
+		}
+		paths[kv[0]] = kv[1]
+	}
+
+	return paths, nil
+}
+
+func run(ctx *cli.Context) error {
+	var err error
 	var gstore store.Store
+	var registry *relation.Registry
 	storeID := "kctl"
 
+	if len(relations) > 0 {
+		registry, err = relation.LoadRegistry(relations)
+		if err != nil {
+			return fmt.Errorf("failed to load relations: %w", err)
+		}
+	}
+
+	// the store styles the edges of the registered relations
+	sopts := store.Options{DOTOptions: store.DOTOptions{Relations: registry}}
+
 	switch graphStore {
 	case "memory":
-		gstore, err = memory.NewStore(storeID, store.Options{})
+		gstore, err = memory.NewStore(storeID, sopts)
 		if err != nil {
 			return err
 		}
 	default:
-		gstore, err = memory.NewStore(storeID, store.Options{})
+		gstore, err = memory.NewStore(storeID, sopts)
 		if err != nil {
 			return err
 		}
 	}
 
-	k, err := kraph.New(kraph.Store(gstore))
+	kopts := []kraph.Option{
+		kraph.Store(gstore),
+		kraph.FilterLinks(filterLinks),
+		kraph.APIGraph(apiGraph),
+	}
+
+	if registry != nil {
+		kopts = append(kopts, kraph.Relations(registry))
+	}
+
+	contextNames := []string{""}
+	if len(contexts) > 0 {
+		contextNames = strings.Split(contexts, ",")
+	}
+
+	fieldPaths, err := parseFields(fields.Value())
 	if err != nil {
-		return fmt.Errorf("failed to create kraph: %w", err)
+		return err
 	}
 
-	var filters []kraph.Filter
-	if len(kinds) > 0 && kinds != "all" {
-		for _, kind := range strings.Split(kinds, ",") {
-			filters = append(filters,
-				func(object api.Object) bool { return object.Resource().Kind() == kind },
-			)
+	// every cluster extracts the reference fields of its own schema
+	clusterFields := make(map[string]map[string]string)
+
+	if openapiRefs {
+		linker := kraph.FieldLinker{}
+
+		for _, context := range contextNames {
+			paths, refs, err := schemaRefs(context)
+			if err != nil {
+				return err
+			}
+
+			cfields := make(map[string]string, len(fieldPaths)+len(paths))
+			for name, path := range fieldPaths {
+				cfields[name] = path
+			}
+			for name, path := range paths {
+				cfields[name] = path
+			}
+
+			clusterFields[context] = cfields
+			linker.Refs = append(linker.Refs, refs...)
 		}
-	}
 
-	client := k8s.NewClient(ctx.Context, discClient.Discovery(), dynClient, k8s.Namespace(namespace))
+		kopts = append(kopts, kraph.Linkers(linker))
+	}
 
-	// TODO: Build now returns store.Graph
-	// there is no need to call k.Store() as below
-	_, err = k.Build(client, filters...)
+	k, err := kraph.New(kopts...)
 	if err != nil {
-		return fmt.Errorf("failed to build kraph: %w", err)
+		return fmt.Errorf("failed to create kraph: %w", err)
 	}
 
-	graphOut, err := graphToOut(k.Store(), format)
+	var clients []api.Client
+	var apis []api.API
+
+	for _, context := range contextNames {
+		paths := fieldPaths
+		if cfields, ok := clusterFields[context]; ok {
+			paths = cfields
+		}
+
+		client, err := newClient(ctx, context,
+			k8s.Namespace(namespace),
+			k8s.Fields(paths),
+			k8s.AllVersions(allVersions),
+			k8s.Subresources(subresources),
+		)
+		if err != nil {
+			return err
+		}
+
+		a, err := client.Discover()
+		if err != nil {
+			return fmt.Errorf("failed discovering API: %w", err)
+		}
+
+		clients = append(clients, discovered{Client: client, api: a})
+		apis = append(apis, a)
+	}
+
+	filters, err := buildFilters(apis)
 	if err != nil {
 		return err
 	}
 
-	fmt.Println(graphOut)
+	g, err := k.BuildAll(clients, filters...)
+	if err != nil {
+		return fmt.Errorf("failed to build kraph: %w", err)
+	}
+
+	if len(root) > 0 {
+		g, err = subGraph(k.Store(), apis, root, depth)
+		if err != nil {
+			return err
+		}
+	}
 
-	return nil
+	return writeGraph(os.Stdout, g, format)
 }
@@ -0,0 +1,69 @@
+package build
+
+import (
+	"testing"
+
+	"github.com/milosgajdos/kraph/pkg/api"
+	"github.com/milosgajdos/kraph/pkg/api/gen"
+	"github.com/milosgajdos/kraph/pkg/store"
+	"github.com/milosgajdos/kraph/pkg/store/memory"
+)
+
+func TestSubGraph(t *testing.T) {
+	deploy := gen.NewResource("deployments", "Deployment", "apps", "v1", true)
+	pod := gen.NewResource("pods", "Pod", "", "v1", true)
+
+	a := gen.NewAPI("k8s")
+	for _, res := range []api.Resource{deploy, pod} {
+		a.AddResource(res)
+		a.IndexPath(res, res.Name())
+	}
+
+	s, err := memory.NewStore("test", store.NewOptions())
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+
+	objs := []api.Object{
+		gen.NewMockObject("deployUID", "foo", "ns", deploy),
+		gen.NewMockObject("podUID", "foo", "ns", pod),
+		gen.NewMockObject("otherUID", "bar", "ns", pod),
+	}
+
+	var nodes []store.Node
+	for _, obj := range objs {
+		e, err := s.Add(obj, store.NewAddOptions())
+		if err != nil {
+			t.Fatalf("failed to add %s: %v", obj.UID(), err)
+		}
+		nodes = append(nodes, e.(store.Node))
+	}
+
+	if _, err := s.Link(nodes[0], nodes[1], store.NewLinkOptions()); err != nil {
+		t.Fatalf("failed to link nodes: %v", err)
+	}
+
+	for _, root := range []string{"deployment/ns/foo", "Deployment/ns/foo", "deployments/ns/foo"} {
+		g, err := subGraph(s, []api.API{a}, root, 1)
+		if err != nil {
+			t.Fatalf("%s: failed to get subgraph: %v", root, err)
+		}
+
+		sub, err := g.Nodes()
+		if err != nil {
+			t.Fatalf("%s: failed to get subgraph nodes: %v", root, err)
+		}
+
+		if len(sub) != 2 {
+			t.Errorf("%s: expected nodes: %d, got: %d", root, 2, len(sub))
+		}
+	}
+
+	if _, err := subGraph(s, []api.API{a}, "service/ns/foo", 1); err == nil {
+		t.Errorf("expected error for unknown root kind")
+	}
+
+	if _, err := subGraph(s, []api.API{a}, "deployment/ns/bar", 1); err == nil {
+		t.Errorf("expected error for missing root")
+	}
+}
@@ -0,0 +1,38 @@
+package build
+
+import (
+	"github.com/milosgajdos/kraph/pkg/api/openapi"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	schemaFile string
+)
+
+// OpenAPI returns OpenAPI schema subcommand for build command
+func OpenAPI() *cli.Command {
+	return &cli.Command{
+		Name:     "openapi",
+		Category: "build",
+		Usage:    "openapi schema graph",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:        "file",
+				Aliases:     []string{"F"},
+				Usage:       "Path to an OpenAPI v2 or v3 document or custom resource definitions",
+				Required:    true,
+				Destination: &schemaFile,
+			},
+			&cli.StringFlag{
+				Name:        "format",
+				Aliases:     []string{"f"},
+				Value:       "dot",
+				Usage:       "print graph in a given format: dot, nodes, edges",
+				Destination: &format,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			return runClient(openapi.NewClient(openapi.FileLoader(schemaFile)))
+		},
+	}
+}
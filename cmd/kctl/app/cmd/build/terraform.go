@@ -0,0 +1,38 @@
+package build
+
+import (
+	"github.com/milosgajdos/kraph/pkg/api/terraform"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	stateFile string
+)
+
+// Terraform returns Terraform subcommand for build command
+func Terraform() *cli.Command {
+	return &cli.Command{
+		Name:     "terraform",
+		Aliases:  []string{"tf"},
+		Category: "build",
+		Usage:    "terraform state graph",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:        "state",
+				Value:       "terraform.tfstate",
+				Usage:       "Path to a terraform state file",
+				Destination: &stateFile,
+			},
+			&cli.StringFlag{
+				Name:        "format",
+				Aliases:     []string{"f"},
+				Value:       "dot",
+				Usage:       "print graph in a given format: dot, nodes, edges",
+				Destination: &format,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			return runClient(terraform.NewClient(stateFile))
+		},
+	}
+}
@@ -2,15 +2,17 @@ package kraph
 
 import (
 	"fmt"
+	"strconv"
 
 	"github.com/milosgajdos/kraph/pkg/api"
 	"github.com/milosgajdos/kraph/pkg/attrs"
-	"github.com/milosgajdos/kraph/pkg/query"
+	"github.com/milosgajdos/kraph/pkg/relation"
 	"github.com/milosgajdos/kraph/pkg/store"
 )
 
 type kraph struct {
 	store store.Store
+	opts  Options
 }
 
 // New creates new kraph and returns it
@@ -26,38 +28,79 @@ func New(opts ...Option) (Kraph, error) {
 
 	return &kraph{
 		store: o.Store,
+		opts:  *o,
 	}, nil
 }
 
-// linkObject links obj to all of its neighbours and sets their relation to rel.
-func (k *kraph) linkObjects(obj api.Object, rel api.Relation, neighbs []api.Object) error {
-	from, err := k.store.Add(obj, store.AddOptions{})
+// link links the objects in relationship rel in transaction tx.
+// Relationships to the objects which do not pass the filters are skipped if links are filtered.
+func (k *kraph) link(tx store.Tx, rel Relationship, filters ...Filter) error {
+	if skipGraph(rel.From, filters...) {
+		return nil
+	}
+
+	if k.opts.FilterLinks && skipGraph(rel.To, filters...) {
+		return nil
+	}
+
+	from, err := tx.Add(rel.From, store.AddOptions{})
 	if err != nil {
 		return err
 	}
 
-	for _, o := range neighbs {
-		to, err := k.store.Add(o, store.AddOptions{})
-		if err != nil {
-			return err
-		}
+	to, err := tx.Add(rel.To, store.AddOptions{})
+	if err != nil {
+		return err
+	}
+
+	var name string
+	if rel.Relation != nil {
+		name = rel.Relation.String()
+	}
+
+	attrs := attrs.New()
 
-		attrs := attrs.New()
-		if rel.String() != "" {
-			attrs.Set("relation", rel.String())
+	var r *relation.Relation
+	if k.opts.Relations != nil {
+		if reg, ok := k.opts.Relations.Get(name); ok {
+			r = &reg
 		}
-		attrs.Set("weight", fmt.Sprintf("%f", store.DefaultWeight))
+	}
 
-		opts := store.LinkOptions{Attrs: attrs, Weight: store.DefaultWeight}
-		if _, err := k.store.Link(from, to, opts); err != nil {
-			return err
+	// relationship weight overrides the weight of its relation
+	w := store.DefaultWeight
+	switch {
+	case rel.Weight != nil:
+		w = *rel.Weight
+	case r != nil && r.Weight != nil:
+		w = *r.Weight
+	}
+
+	if r != nil {
+		attrs.Set(store.DirectedAttr, strconv.FormatBool(r.Directed))
+	}
+
+	if rel.Attrs != nil {
+		for _, key := range rel.Attrs.Keys() {
+			attrs.Set(key, rel.Attrs.Get(key))
 		}
 	}
 
+	if name != "" {
+		attrs.Set("relation", name)
+	}
+	attrs.Set("weight", fmt.Sprintf("%f", w))
+
+	opts := store.LinkOptions{Attrs: attrs, Weight: w, Relation: name}
+	if _, err := tx.Link(from, to, opts); err != nil {
+		return err
+	}
+
 	return nil
 }
 
 // skipGraph skips adding API objects into graph based on defined filters.
+// An object is skipped unless it passes at least one of the filters.
 func skipGraph(object api.Object, filters ...Filter) bool {
 	if len(filters) == 0 {
 		return false
@@ -72,55 +115,92 @@ func skipGraph(object api.Object, filters ...Filter) bool {
 	return true
 }
 
-// buildGraph builds a graph from given topology and returns it.
-func (k *kraph) buildGraph(top api.Top, filters ...Filter) (store.Graph, error) {
+// buildGraph builds a graph from given topology of API a in transaction tx.
+func (k *kraph) buildGraph(tx store.Tx, a api.API, top api.Top, filters ...Filter) error {
 	for _, object := range top.Objects() {
 		if skipGraph(object, filters...) {
 			continue
 		}
 
-		if len(object.Links()) == 0 {
-			if _, err := k.store.Add(object, store.AddOptions{}); err != nil {
-				return nil, fmt.Errorf("error adding node: %w", err)
-			}
-			continue
+		if _, err := tx.Add(object, store.AddOptions{}); err != nil {
+			return fmt.Errorf("error adding node: %w", err)
 		}
+	}
 
-		for _, link := range object.Links() {
-			uid := link.To()
-
-			q := query.Build().UID(uid, query.UIDEqFunc(uid))
+	for _, linker := range k.opts.Linkers {
+		rels, err := linker.Link(top)
+		if err != nil {
+			return fmt.Errorf("error linking objects: %w", err)
+		}
 
-			objs, err := top.Get(q)
-			if err != nil {
-				return nil, err
+		for _, rel := range rels {
+			if err := k.link(tx, rel, filters...); err != nil {
+				return fmt.Errorf("error linking objects: %w", err)
 			}
+		}
+	}
 
-			if err := k.linkObjects(object, link.Relation(), objs); err != nil {
-				return nil, err
-			}
+	if k.opts.APIGraph {
+		if err := k.buildAPIGraph(tx, a, top, filters...); err != nil {
+			return fmt.Errorf("error building API graph: %w", err)
 		}
 	}
 
-	return k.store, nil
+	return nil
 }
 
 // Build builds a graph of API object using the client and returns it.
+// The graph is built in a single store transaction: if the build fails
+// the store is left in the state it was in before the build started.
 func (k *kraph) Build(client api.Client, filters ...Filter) (store.Graph, error) {
+	return k.BuildAll([]api.Client{client}, filters...)
+}
+
+// BuildAll builds a single graph of API objects using all the clients and returns it.
+// The API objects of different clients should come from different API sources
+// so their UIDs do not collide. The objects are linked within their own API only.
+// The graph is built in a single store transaction like in Build.
+func (k *kraph) BuildAll(clients []api.Client, filters ...Filter) (store.Graph, error) {
 	// TODO: reset the graph before building
 	// This will allow to run k.Build multiple times
 	// each time building the graph from scratch
-	api, err := client.Discover()
-	if err != nil {
-		return nil, fmt.Errorf("failed discovering API: %w", err)
+	var apis []api.API
+	var tops []api.Top
+
+	for _, client := range clients {
+		api, err := client.Discover()
+		if err != nil {
+			return nil, fmt.Errorf("failed discovering API: %w", err)
+		}
+
+		top, err := client.Map(api)
+		if err != nil {
+			return nil, fmt.Errorf("failed mapping API: %w", err)
+		}
+
+		apis = append(apis, api)
+		tops = append(tops, top)
 	}
 
-	top, err := client.Map(api)
+	tx, err := k.store.Begin()
 	if err != nil {
-		return nil, fmt.Errorf("failed mapping API: %w", err)
+		return nil, fmt.Errorf("failed starting transaction: %w", err)
+	}
+
+	for i, top := range tops {
+		if err := k.buildGraph(tx, apis[i], top, filters...); err != nil {
+			if rerr := tx.Rollback(); rerr != nil {
+				return nil, fmt.Errorf("failed rolling back transaction: %v: %w", rerr, err)
+			}
+			return nil, err
+		}
 	}
 
-	return k.buildGraph(top, filters...)
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed committing transaction: %w", err)
+	}
+
+	return k.store, nil
 }
 
 // Store returns kraph stor
@@ -1,11 +1,16 @@
 package kraph
 
 import (
+	"errors"
+	"math/big"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/milosgajdos/kraph/pkg/api"
 	"github.com/milosgajdos/kraph/pkg/api/gen"
+	"github.com/milosgajdos/kraph/pkg/query"
+	"github.com/milosgajdos/kraph/pkg/relation"
 	"github.com/milosgajdos/kraph/pkg/store"
 	"github.com/milosgajdos/kraph/pkg/store/memory"
 )
@@ -52,6 +57,238 @@ func TestBuild(t *testing.T) {
 	}
 }
 
+func TestBuildFilterLinks(t *testing.T) {
+	client, err := gen.NewMockClient(resPath, objPath)
+	if err != nil {
+		t.Fatalf("failed to build mock client: %v", err)
+	}
+
+	tests := []struct {
+		filterLinks bool
+		expKinds    map[string]bool
+	}{
+		{false, map[string]bool{"fooKind": true, "barKind": true}},
+		{true, map[string]bool{"fooKind": true}},
+	}
+
+	for _, test := range tests {
+		m, err := memory.NewStore("memory", store.Options{})
+		if err != nil {
+			t.Fatalf("failed to create memory store: %v", err)
+		}
+
+		k, err := New(Store(m), FilterLinks(test.filterLinks))
+		if err != nil {
+			t.Fatalf("failed to create kraph: %v", err)
+		}
+
+		g, err := k.Build(client, KindFilter("fooKind"))
+		if err != nil {
+			t.Fatalf("failed to build graph: %v", err)
+		}
+
+		nodes, err := g.Nodes()
+		if err != nil {
+			t.Fatalf("failed to get graph nodes: %v", err)
+		}
+
+		kinds := make(map[string]bool)
+		for _, n := range nodes {
+			obj := n.Metadata().Get("object").(api.Object)
+			kinds[obj.Resource().Kind()] = true
+		}
+
+		if !reflect.DeepEqual(kinds, test.expKinds) {
+			t.Errorf("filter links %v: expected kinds: %v, got: %v", test.filterLinks, test.expKinds, kinds)
+		}
+	}
+}
+
+func TestBuildRelations(t *testing.T) {
+	client, err := gen.NewMockClient(resPath, objPath)
+	if err != nil {
+		t.Fatalf("failed to build mock client: %v", err)
+	}
+
+	weight, zero := 0.5, 0.0
+
+	rel := relation.Relation{
+		Name:     "foo-bar",
+		Weight:   &weight,
+		Directed: true,
+		Style:    relation.Style{Color: "red"},
+	}
+
+	r, err := relation.NewRegistry(rel, relation.Relation{Name: "foo-foo", Weight: &zero})
+	if err != nil {
+		t.Fatalf("failed to create relation registry: %v", err)
+	}
+
+	m, err := memory.NewStore("memory", store.Options{DOTOptions: store.DOTOptions{Relations: r}})
+	if err != nil {
+		t.Fatalf("failed to create memory store: %v", err)
+	}
+
+	k, err := New(Store(m), Relations(r))
+	if err != nil {
+		t.Fatalf("failed to create kraph: %v", err)
+	}
+
+	if _, err := k.Build(client); err != nil {
+		t.Fatalf("failed to build graph: %v", err)
+	}
+
+	tests := []struct {
+		from     string
+		to       string
+		weight   float64
+		directed string
+	}{
+		{"fooNs/fooKind/foo1", "global/barKind/bar5", weight, "true"},
+		{"fooNs/fooKind/foo1", "fooNs/fooKind/foo4", zero, "false"},
+		{"global/barKind/bar5", "rndNs/rndKind/rnd2", store.DefaultWeight, ""},
+	}
+
+	for _, test := range tests {
+		edges, err := m.Edges(test.from, test.to)
+		if err != nil {
+			t.Fatalf("failed to get edges %s - %s: %v", test.from, test.to, err)
+		}
+
+		if len(edges) != 1 {
+			t.Fatalf("expected edges: %d, got: %d", 1, len(edges))
+		}
+
+		if w := edges[0].Weight(); big.NewFloat(w).Cmp(big.NewFloat(test.weight)) != 0 {
+			t.Errorf("expected weight: %f, got: %f", test.weight, w)
+		}
+
+		if d := edges[0].Attrs().Get(store.DirectedAttr); d != test.directed {
+			t.Errorf("expected directed: %q, got: %q", test.directed, d)
+		}
+
+		// relation styles are applied by the exporters
+		if c := edges[0].Attrs().Get("color"); c != "" {
+			t.Errorf("expected no color attribute, got: %s", c)
+		}
+	}
+
+	dot, err := m.DOT()
+	if err != nil {
+		t.Fatalf("failed to get DOT graph: %v", err)
+	}
+
+	if !strings.Contains(dot, "color=red") {
+		t.Errorf("expected %s relation edges to be styled", rel.Name)
+	}
+}
+
+func TestBuildFailure(t *testing.T) {
+	client, err := gen.NewMockClient(resPath, objPath)
+	if err != nil {
+		t.Fatalf("failed to build mock client: %v", err)
+	}
+
+	m, err := memory.NewStore("memory", store.Options{})
+	if err != nil {
+		t.Fatalf("failed to create memory store: %v", err)
+	}
+
+	errLink := errors.New("link error")
+	linker := LinkerFunc(func(api.Top) ([]Relationship, error) {
+		return nil, errLink
+	})
+
+	k, err := New(Store(m), Linkers(linker))
+	if err != nil {
+		t.Fatalf("failed to create kraph: %v", err)
+	}
+
+	if _, err := k.Build(client); !errors.Is(err, errLink) {
+		t.Fatalf("expected error: %v, got: %v", errLink, err)
+	}
+
+	nodes, err := m.Nodes()
+	if err != nil {
+		t.Fatalf("failed to get graph nodes: %v", err)
+	}
+
+	if len(nodes) != 0 {
+		t.Errorf("expected nodes: %d, got: %d", 0, len(nodes))
+	}
+}
+
+// sourceClient maps two linked objects from its API source
+type sourceClient struct {
+	src string
+}
+
+func (c sourceClient) Discover() (api.API, error) {
+	return gen.NewAPI(c.src), nil
+}
+
+func (c sourceClient) Map(a api.API) (api.Top, error) {
+	res := gen.NewResource("pods", "pod", "", "v1", true)
+	top := gen.NewTop()
+
+	foo := gen.NewMockObject(c.src+"/foo", "foo", "default", res, gen.FromSource(a.Source()))
+	bar := gen.NewMockObject(c.src+"/bar", "bar", "default", res, gen.FromSource(a.Source()))
+	foo.Link(bar.UID(), gen.NewRelation("foo-bar"))
+
+	top.Add(foo)
+	top.Add(bar)
+
+	return top, nil
+}
+
+func TestBuildAll(t *testing.T) {
+	m, err := memory.NewStore("memory", store.Options{})
+	if err != nil {
+		t.Fatalf("failed to create memory store: %v", err)
+	}
+
+	k, err := New(Store(m))
+	if err != nil {
+		t.Fatalf("failed to create kraph: %v", err)
+	}
+
+	clients := []api.Client{sourceClient{"a"}, sourceClient{"b"}}
+
+	if _, err := k.BuildAll(clients); err != nil {
+		t.Fatalf("failed to build graph: %v", err)
+	}
+
+	nodes, err := m.Nodes()
+	if err != nil {
+		t.Fatalf("failed to get graph nodes: %v", err)
+	}
+
+	if len(nodes) != 4 {
+		t.Errorf("expected nodes: %d, got: %d", 4, len(nodes))
+	}
+
+	for _, src := range []string{"a", "b"} {
+		res, err := m.Query(query.Build().Entity(query.Node).Source(src))
+		if err != nil {
+			t.Fatalf("failed to query nodes: %v", err)
+		}
+
+		if len(res) != 2 {
+			t.Errorf("source %s: expected nodes: %d, got: %d", src, 2, len(res))
+		}
+
+		for _, n := range res {
+			if s := n.Attrs().Get(store.SourceAttr); s != src {
+				t.Errorf("expected source attr: %s, got: %s", src, s)
+			}
+		}
+
+		if _, err := m.Edges(src+"/foo", src+"/bar"); err != nil {
+			t.Errorf("source %s: failed to get edges: %v", src, err)
+		}
+	}
+}
+
 func TestStore(t *testing.T) {
 	m, err := memory.NewStore("memory", store.Options{})
 	if err != nil {
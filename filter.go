@@ -0,0 +1,137 @@
+package kraph
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/milosgajdos/kraph/pkg/api"
+)
+
+// Filter returns true if the API object should be added to the graph
+type Filter func(api.Object) bool
+
+// And returns Filter which matches if all the given filters match.
+// It always matches if no filters are given.
+func And(filters ...Filter) Filter {
+	return func(object api.Object) bool {
+		for _, filter := range filters {
+			if !filter(object) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// Or returns Filter which matches if any of the given filters match.
+// It never matches if no filters are given.
+func Or(filters ...Filter) Filter {
+	return func(object api.Object) bool {
+		for _, filter := range filters {
+			if filter(object) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Not returns Filter which negates the given filter.
+func Not(filter Filter) Filter {
+	return func(object api.Object) bool {
+		return !filter(object)
+	}
+}
+
+// NsFilter returns Filter which matches objects in any of the given namespaces.
+func NsFilter(namespaces ...string) Filter {
+	return func(object api.Object) bool {
+		return api.StringIn(object.Namespace(), namespaces)
+	}
+}
+
+// KindFilter returns Filter which matches objects of any of the given kinds.
+// Kinds are matched case insensitively.
+func KindFilter(kinds ...string) Filter {
+	return func(object api.Object) bool {
+		if object.Resource() == nil {
+			return false
+		}
+		return api.StringIn(object.Resource().Kind(), kinds)
+	}
+}
+
+// GroupFilter returns Filter which matches objects of any of the given API groups.
+// Groups are matched case insensitively.
+func GroupFilter(groups ...string) Filter {
+	return func(object api.Object) bool {
+		if object.Resource() == nil {
+			return false
+		}
+		return api.StringIn(object.Resource().Group(), groups)
+	}
+}
+
+// ResourceFilter returns Filter which matches objects of any of the given API resources.
+// Resources are matched by their names, groups and versions case insensitively.
+func ResourceFilter(resources ...api.Resource) Filter {
+	return func(object api.Object) bool {
+		res := object.Resource()
+		if res == nil {
+			return false
+		}
+
+		for _, r := range resources {
+			if strings.EqualFold(res.Name(), r.Name()) &&
+				strings.EqualFold(res.Group(), r.Group()) &&
+				strings.EqualFold(res.Version(), r.Version()) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// CategoryFilter returns Filter which matches objects whose resource belongs to any of the given categories.
+// Categories are matched case insensitively.
+func CategoryFilter(categories ...string) Filter {
+	return func(object api.Object) bool {
+		if object.Resource() == nil {
+			return false
+		}
+
+		for _, category := range object.Resource().Categories() {
+			if api.StringIn(category, categories) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// LabelFilter returns Filter which matches objects which have all the given labels set.
+func LabelFilter(selector map[string]string) Filter {
+	return func(object api.Object) bool {
+		labels := object.Labels()
+		for k, v := range selector {
+			if val, ok := labels[k]; !ok || val != v {
+				return false
+			}
+		}
+
+		return true
+	}
+}
+
+// NameRegexFilter returns Filter which matches objects whose name matches expr.
+// It returns error if expr fails to compile.
+func NameRegexFilter(expr string) (Filter, error) {
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(object api.Object) bool {
+		return re.MatchString(object.Name())
+	}, nil
+}
@@ -0,0 +1,86 @@
+package kraph
+
+import (
+	"testing"
+
+	"github.com/milosgajdos/kraph/pkg/api"
+	"github.com/milosgajdos/kraph/pkg/api/gen"
+)
+
+func TestFilterCombinators(t *testing.T) {
+	obj := gen.NewMockObject("uid", "name", "ns", gen.NewMockResource("pods", "Pod", "", "v1", true))
+
+	yes := func(api.Object) bool { return true }
+	no := func(api.Object) bool { return false }
+
+	tests := []struct {
+		filter   Filter
+		expected bool
+	}{
+		{And(), true},
+		{And(yes, yes), true},
+		{And(yes, no), false},
+		{Or(), false},
+		{Or(no, yes), true},
+		{Or(no, no), false},
+		{Not(yes), false},
+		{Not(no), true},
+		{And(Or(no, yes), Not(no)), true},
+	}
+
+	for i, test := range tests {
+		if res := test.filter(obj); res != test.expected {
+			t.Errorf("test %d: expected: %v, got: %v", i, test.expected, res)
+		}
+	}
+}
+
+func TestFilters(t *testing.T) {
+	res := gen.NewMockResource("deployments", "Deployment", "apps", "v1", true)
+	obj := gen.NewMockObject("uid", "frontend-7d9c", "prod", res)
+
+	nameRe, err := NameRegexFilter("^frontend-")
+	if err != nil {
+		t.Fatalf("failed to create name regex filter: %v", err)
+	}
+
+	if _, err := NameRegexFilter("(["); err == nil {
+		t.Errorf("expected error for invalid regex")
+	}
+
+	tests := []struct {
+		object   api.Object
+		filter   Filter
+		expected bool
+	}{
+		{obj, NsFilter("dev", "prod"), true},
+		{obj, NsFilter("dev"), false},
+		{obj, KindFilter("deployment"), true},
+		{obj, KindFilter("pod", "service"), false},
+		{obj, GroupFilter("apps"), true},
+		{obj, GroupFilter(""), false},
+		{obj, ResourceFilter(res), true},
+		{obj, ResourceFilter(gen.NewMockResource("deployments", "Deployment", "apps", "v1beta1", true)), false},
+		{obj, ResourceFilter(), false},
+		{gen.NewMockObject("uid", "name", "ns", nil), ResourceFilter(res), false},
+		{obj, CategoryFilter("all"), false},
+		{gen.NewMockObject("uid", "name", "ns", gen.NewResource("deployments", "Deployment", "apps", "v1", true,
+			gen.Categories("all"))), CategoryFilter("ALL"), true},
+		{gen.NewMockObject("uid", "name", "ns", nil), CategoryFilter("all"), false},
+		{obj, nameRe, true},
+		{gen.NewMockObject("uid", "backend", "prod", res), nameRe, false},
+		{gen.NewMockObject("uid", "name", "ns", nil), KindFilter("pod"), false},
+		{obj, LabelFilter(nil), true},
+		{obj, LabelFilter(map[string]string{"app": "web"}), false},
+		{gen.NewMockObject("uid", "name", "ns", res, gen.Labels(map[string]string{"app": "web", "tier": "fe"})),
+			LabelFilter(map[string]string{"app": "web"}), true},
+		{gen.NewMockObject("uid", "name", "ns", res, gen.Labels(map[string]string{"app": "db"})),
+			LabelFilter(map[string]string{"app": "web"}), false},
+	}
+
+	for i, test := range tests {
+		if res := test.filter(test.object); res != test.expected {
+			t.Errorf("test %d: expected: %v, got: %v", i, test.expected, res)
+		}
+	}
+}
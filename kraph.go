@@ -2,16 +2,17 @@ package kraph
 
 import (
 	"github.com/milosgajdos/kraph/pkg/api"
+	"github.com/milosgajdos/kraph/pkg/relation"
 	"github.com/milosgajdos/kraph/pkg/store"
 	"github.com/milosgajdos/kraph/pkg/store/memory"
 )
 
-type Filter func(api.Object) bool
-
 // Kraph builds a graph of API objects
 type Kraph interface {
 	// Build builds a graph and returns graph store
 	Build(api.Client, ...Filter) (store.Graph, error)
+	// BuildAll builds a single graph of the APIs of all the clients and returns graph store
+	BuildAll([]api.Client, ...Filter) (store.Graph, error)
 	// Store returns graph store
 	Store() store.Store
 }
@@ -19,6 +20,18 @@ type Kraph interface {
 // Options are kraph options
 type Options struct {
 	Store store.Store
+	// FilterLinks applies build filters to link endpoints, too.
+	// When true, filtered out objects are never added to the graph,
+	// not even as neighbours of the objects which passed the filters.
+	FilterLinks bool
+	// Linkers discover relationships between API objects
+	Linkers []Linker
+	// Relations is relation registry which weights the edges of the relations.
+	// Relation styles are applied by the store exporters, see store.DOTOptions.
+	Relations *relation.Registry
+	// APIGraph adds API groups, versions and resources to the graph
+	// and links every object to its resource.
+	APIGraph bool
 }
 
 // Option is functional kraph option
@@ -31,6 +44,34 @@ func Store(s store.Store) Option {
 	}
 }
 
+// FilterLinks configures filtering of link endpoints
+func FilterLinks(f bool) Option {
+	return func(o *Options) {
+		o.FilterLinks = f
+	}
+}
+
+// Linkers appends linkers to the default kraph linkers
+func Linkers(l ...Linker) Option {
+	return func(o *Options) {
+		o.Linkers = append(o.Linkers, l...)
+	}
+}
+
+// Relations configures relation registry
+func Relations(r *relation.Registry) Option {
+	return func(o *Options) {
+		o.Relations = r
+	}
+}
+
+// APIGraph configures adding the API to the graph
+func APIGraph(g bool) Option {
+	return func(o *Options) {
+		o.APIGraph = g
+	}
+}
+
 // NewOptions creates default options and returns it
 func NewOptions() (*Options, error) {
 	m, err := memory.NewStore("default", store.Options{})
@@ -38,7 +79,14 @@ func NewOptions() (*Options, error) {
 		return nil, err
 	}
 
+	r, err := relation.NewRegistry()
+	if err != nil {
+		return nil, err
+	}
+
 	return &Options{
-		Store: m,
+		Store:     m,
+		Linkers:   []Linker{ObjectLinker{}},
+		Relations: r,
 	}, nil
 }
@@ -0,0 +1,141 @@
+package kraph
+
+import (
+	"strings"
+
+	"github.com/milosgajdos/kraph/pkg/api"
+	"github.com/milosgajdos/kraph/pkg/attrs"
+	"github.com/milosgajdos/kraph/pkg/query"
+	"github.com/milosgajdos/kraph/pkg/store"
+)
+
+// Relationship is a directed relationship between two API objects
+type Relationship struct {
+	// From is the linking object
+	From api.Object
+	// To is the linked object
+	To api.Object
+	// Relation is the relationship relation
+	Relation api.Relation
+	// Weight is the relationship weight. If it is nil the weight
+	// of the registered relation or store.DefaultWeight is used.
+	Weight *float64
+	// Attrs are extra relationship attributes
+	Attrs attrs.Attrs
+}
+
+// Linker discovers relationships between API objects
+type Linker interface {
+	// Link returns relationships between the objects in the topology
+	Link(api.Top) ([]Relationship, error)
+}
+
+// LinkerFunc is an adapter which allows to use ordinary functions as Linkers
+type LinkerFunc func(api.Top) ([]Relationship, error)
+
+// Link calls f(top)
+func (f LinkerFunc) Link(top api.Top) ([]Relationship, error) {
+	return f(top)
+}
+
+// ObjectLinker links API objects using their own links
+type ObjectLinker struct{}
+
+// Link returns relationships defined by object links
+func (ObjectLinker) Link(top api.Top) ([]Relationship, error) {
+	var rels []Relationship
+
+	for _, object := range top.Objects() {
+		for _, link := range object.Links() {
+			uid := link.To()
+
+			q := query.Build().UID(uid)
+
+			objs, err := top.Get(q)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, o := range objs {
+				rels = append(rels, Relationship{
+					From:     object,
+					To:       o,
+					Relation: link.Relation(),
+				})
+			}
+		}
+	}
+
+	return rels, nil
+}
+
+// FieldRef is a reference of API objects to the objects
+// of another kind by the names stored in an object field
+type FieldRef struct {
+	// Kind is the kind of the referencing objects
+	Kind string
+	// Field is the name of the object field which holds the names
+	// of the referenced objects separated by white space
+	Field string
+	// To is the kind of the referenced objects
+	To string
+	// Relation is the relation of the references
+	Relation api.Relation
+	// Source is the API source of the referencing objects.
+	// If it is empty the objects of any source are linked.
+	Source string
+}
+
+// FieldLinker links API objects to the objects referenced by their fields.
+// The referenced objects are looked up in the namespace of the referencing
+// object first and then in the global namespace.
+type FieldLinker struct {
+	// Refs are the field references
+	Refs []FieldRef
+}
+
+// Link returns relationships defined by the field references
+func (f FieldLinker) Link(top api.Top) ([]Relationship, error) {
+	var rels []Relationship
+
+	for _, ref := range f.Refs {
+		objects, err := top.Get(query.Build().Kind(ref.Kind))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, object := range objects {
+			if len(ref.Source) > 0 && store.ObjectSource(object) != ref.Source {
+				continue
+			}
+
+			for _, name := range strings.Fields(object.Fields()[ref.Field]) {
+				for _, ns := range []string{object.Namespace(), api.NsGlobal} {
+					q := query.Build().
+						Namespace(ns).
+						Kind(ref.To).
+						Name(strings.ToLower(name))
+
+					objs, err := top.Get(q)
+					if err != nil {
+						return nil, err
+					}
+
+					for _, o := range objs {
+						rels = append(rels, Relationship{
+							From:     object,
+							To:       o,
+							Relation: ref.Relation,
+						})
+					}
+
+					if len(objs) > 0 {
+						break
+					}
+				}
+			}
+		}
+	}
+
+	return rels, nil
+}
@@ -0,0 +1,236 @@
+package kraph
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/milosgajdos/kraph/pkg/api"
+	"github.com/milosgajdos/kraph/pkg/api/gen"
+	"github.com/milosgajdos/kraph/pkg/attrs"
+	"github.com/milosgajdos/kraph/pkg/query"
+	"github.com/milosgajdos/kraph/pkg/relation"
+	"github.com/milosgajdos/kraph/pkg/store"
+	"github.com/milosgajdos/kraph/pkg/store/memory"
+	"github.com/milosgajdos/kraph/pkg/uuid"
+)
+
+func TestObjectLinker(t *testing.T) {
+	top, err := gen.NewMockTop(objPath)
+	if err != nil {
+		t.Fatalf("failed to create mock top: %v", err)
+	}
+
+	rels, err := ObjectLinker{}.Link(top)
+	if err != nil {
+		t.Fatalf("failed to link objects: %v", err)
+	}
+
+	count := 0
+	for _, object := range top.Objects() {
+		count += len(object.Links())
+	}
+
+	if len(rels) != count {
+		t.Errorf("expected relationships: %d, got: %d", count, len(rels))
+	}
+
+	for _, rel := range rels {
+		if rel.From == nil || rel.To == nil {
+			t.Errorf("expected non-nil relationship endpoints, got: %#v", rel)
+		}
+	}
+}
+
+func TestBuildLinkers(t *testing.T) {
+	client, err := gen.NewMockClient(resPath, objPath)
+	if err != nil {
+		t.Fatalf("failed to build mock client: %v", err)
+	}
+
+	m, err := memory.NewStore("memory", store.Options{})
+	if err != nil {
+		t.Fatalf("failed to create memory store: %v", err)
+	}
+
+	weight := 2.0
+	rel := gen.NewRelation("sameName")
+
+	// link all objects with the same name suffix to each other
+	linker := LinkerFunc(func(top api.Top) ([]Relationship, error) {
+		var rels []Relationship
+		for _, from := range top.Objects() {
+			for _, to := range top.Objects() {
+				if from.UID().String() == to.UID().String() {
+					continue
+				}
+				if from.Name()[len(from.Name())-1] == to.Name()[len(to.Name())-1] {
+					rels = append(rels, Relationship{From: from, To: to, Relation: rel, Weight: &weight})
+				}
+			}
+		}
+		return rels, nil
+	})
+
+	k, err := New(Store(m), Linkers(linker))
+	if err != nil {
+		t.Fatalf("failed to create kraph: %v", err)
+	}
+
+	if _, err := k.Build(client); err != nil {
+		t.Fatalf("failed to build graph: %v", err)
+	}
+
+	foo1, err := m.Node("fooNs/fooKind/foo1")
+	if err != nil {
+		t.Fatalf("failed to get node: %v", err)
+	}
+
+	rnd1, err := m.Node("rndNs/rndKind/rnd1")
+	if err != nil {
+		t.Fatalf("failed to get node: %v", err)
+	}
+
+	edges, err := m.Edges(foo1.UID(), rnd1.UID())
+	if err != nil {
+		t.Fatalf("failed to get edges: %v", err)
+	}
+
+	// the objects are linked to each other in both directions
+	if len(edges) != 2 {
+		t.Fatalf("expected edges: %d, got: %d", 2, len(edges))
+	}
+
+	if edges[0].From().UID() == edges[1].From().UID() {
+		t.Errorf("expected edges in both directions, got: %s -> %s twice", edges[0].From().UID(), edges[0].To().UID())
+	}
+
+	for _, e := range edges {
+		if r := e.Attrs().Get("relation"); r != rel.String() {
+			t.Errorf("expected relation: %s, got: %s", rel, r)
+		}
+
+		if w := e.Weight(); big.NewFloat(w).Cmp(big.NewFloat(weight)) != 0 {
+			t.Errorf("expected weight: %f, got: %f", weight, w)
+		}
+	}
+}
+
+func TestBuildLinkersZeroWeight(t *testing.T) {
+	client, err := gen.NewMockClient(resPath, objPath)
+	if err != nil {
+		t.Fatalf("failed to build mock client: %v", err)
+	}
+
+	m, err := memory.NewStore("memory", store.Options{})
+	if err != nil {
+		t.Fatalf("failed to create memory store: %v", err)
+	}
+
+	weight, zero := 0.5, 0.0
+
+	r, err := relation.NewRegistry(relation.Relation{Name: "zero", Weight: &weight})
+	if err != nil {
+		t.Fatalf("failed to create relation registry: %v", err)
+	}
+
+	linker := LinkerFunc(func(top api.Top) ([]Relationship, error) {
+		objs := top.Objects()
+		return []Relationship{{From: objs[0], To: objs[1], Relation: gen.NewRelation("zero"), Weight: &zero}}, nil
+	})
+
+	k, err := New(Store(m), Relations(r), Linkers(linker))
+	if err != nil {
+		t.Fatalf("failed to create kraph: %v", err)
+	}
+
+	if _, err := k.Build(client); err != nil {
+		t.Fatalf("failed to build graph: %v", err)
+	}
+
+	a := attrs.New()
+	a.Set("relation", "zero")
+
+	it, err := m.QueryEdges(query.Build().Attrs(a))
+	if err != nil {
+		t.Fatalf("failed to query edges: %v", err)
+	}
+	defer it.Close()
+
+	if !it.Next() {
+		t.Fatal("expected zero relation edge")
+	}
+
+	// explicit relationship weight overrides the registered weight
+	if w := it.Edge().Weight(); w != zero {
+		t.Errorf("expected weight: %f, got: %f", zero, w)
+	}
+}
+
+func TestFieldLinker(t *testing.T) {
+	podRes := gen.NewResource("pods", "Pod", "", "v1", true)
+	saRes := gen.NewResource("serviceaccounts", "ServiceAccount", "", "v1", true)
+	nodeRes := gen.NewResource("nodes", "Node", "", "v1", false)
+
+	top := gen.NewTop()
+
+	pod := gen.NewObject(uuid.NewFromString("pod"), "web", "prod", podRes, gen.Fields(map[string]string{
+		"sa":   "Builder",
+		"node": "node1 node2",
+	}))
+	top.Add(pod)
+
+	for _, obj := range []api.Object{
+		gen.NewObject(uuid.NewFromString("sa-prod"), "builder", "prod", saRes),
+		gen.NewObject(uuid.NewFromString("sa-dev"), "builder", "dev", saRes),
+		gen.NewObject(uuid.NewFromString("node1"), "node1", api.NsGlobal, nodeRes),
+		gen.NewObject(uuid.NewFromString("node2"), "node2", api.NsGlobal, nodeRes),
+	} {
+		top.Add(obj)
+	}
+
+	linker := FieldLinker{
+		Refs: []FieldRef{
+			{Kind: "Pod", Field: "sa", To: "ServiceAccount", Relation: gen.NewRelation("serviceAccountName")},
+			{Kind: "Pod", Field: "node", To: "Node", Relation: gen.NewRelation("nodeName")},
+			{Kind: "Pod", Field: "missing", To: "Node", Relation: gen.NewRelation("missing")},
+		},
+	}
+
+	rels, err := linker.Link(top)
+	if err != nil {
+		t.Fatalf("failed to link objects: %v", err)
+	}
+
+	exp := map[string]string{
+		"sa-prod": "serviceAccountName",
+		"node1":   "nodeName",
+		"node2":   "nodeName",
+	}
+
+	if len(rels) != len(exp) {
+		t.Fatalf("expected relationships: %d, got: %d", len(exp), len(rels))
+	}
+
+	for _, rel := range rels {
+		if rel.From.UID().String() != pod.UID().String() {
+			t.Errorf("expected relationship from: %s, got: %s", pod.UID(), rel.From.UID())
+		}
+
+		if r := exp[rel.To.UID().String()]; r != rel.Relation.String() {
+			t.Errorf("%s: expected relation: %s, got: %s", rel.To.UID(), r, rel.Relation)
+		}
+	}
+
+	for i := range linker.Refs {
+		linker.Refs[i].Source = "other"
+	}
+
+	rels, err = linker.Link(top)
+	if err != nil {
+		t.Fatalf("failed to link objects: %v", err)
+	}
+
+	if len(rels) != 0 {
+		t.Errorf("expected relationships: %d, got: %d", 0, len(rels))
+	}
+}
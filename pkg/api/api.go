@@ -22,6 +22,20 @@ type Resource interface {
 	Version() string
 	// Namespaced returns true if the resource is namespaced
 	Namespaced() bool
+	// SingularName returns resource singular name
+	SingularName() string
+	// ShortNames returns resource short names
+	ShortNames() []string
+	// Categories returns the categories the resource belongs to
+	Categories() []string
+	// Verbs returns the verbs supported by the resource
+	Verbs() []string
+	// PreferredVersion returns the preferred version of the resource group
+	PreferredVersion() string
+	// Deprecated returns true if the resource version is deprecated
+	Deprecated() bool
+	// Paths returns all possible variations of the resource paths
+	Paths() []string
 }
 
 // Relation defines remote link relation
@@ -52,6 +66,14 @@ type Object interface {
 	Namespace() string
 	// Resource returns Object API resource
 	Resource() Resource
+	// Labels returns Object labels
+	Labels() map[string]string
+	// Annotations returns Object annotations
+	Annotations() map[string]string
+	// Fields returns Object fields extracted from the API
+	Fields() map[string]string
+	// Source returns the API source of the Object or nil if it is unknown
+	Source() Source
 	// Link links object to another object
 	Link(uuid.UID, Relation)
 	// Links returns all Object links
@@ -72,6 +94,8 @@ type API interface {
 	Resources() []Resource
 	// Get returns all API resources matching the query
 	Get(*query.Query) ([]Resource, error)
+	// Lookup returns all API resources with the given name, short name or path
+	Lookup(string) ([]Resource, error)
 }
 
 // Top is an API topology i.e. the map of Objects
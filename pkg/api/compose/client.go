@@ -0,0 +1,270 @@
+package compose
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+
+	"github.com/ghodss/yaml"
+	"github.com/milosgajdos/kraph/pkg/api"
+	"github.com/milosgajdos/kraph/pkg/api/gen"
+	"github.com/milosgajdos/kraph/pkg/errors"
+	"github.com/milosgajdos/kraph/pkg/uuid"
+)
+
+const (
+	// Group is compose API group
+	Group = "compose"
+	// Version is compose API version
+	Version = "v1"
+	// DependsOnRel links services to the services they depend on
+	DependsOnRel = "dependsOn"
+	// AttachesToRel links services to the networks they are attached to
+	AttachesToRel = "attachesTo"
+	// MountsRel links services to the volumes, configs and secrets they mount
+	MountsRel = "mounts"
+	// ExposesRel links services to the networks they expose ports on
+	ExposesRel = "exposes"
+	// defaultNetwork is the network services are attached to by default
+	defaultNetwork = "default"
+)
+
+// resourceKinds are compose API resource names and kinds
+var resourceKinds = []struct {
+	name string
+	kind string
+}{
+	{"services", "service"},
+	{"networks", "network"},
+	{"volumes", "volume"},
+	{"configs", "config"},
+	{"secrets", "secret"},
+}
+
+type client struct {
+	// path is compose file path
+	path string
+	// opts are client options
+	opts Options
+}
+
+// NewClient returns new compose API client for the compose file at path
+func NewClient(path string, opts ...Option) *client {
+	copts := Options{}
+	for _, apply := range opts {
+		apply(&copts)
+	}
+
+	return &client{
+		path: path,
+		opts: copts,
+	}
+}
+
+// Discover returns compose API
+func (c *client) Discover() (api.API, error) {
+	a := gen.NewAPI("compose")
+
+	for _, rk := range resourceKinds {
+		res := gen.NewResource(rk.name, rk.kind, Group, Version, true)
+		a.AddResource(res)
+		for _, path := range res.Paths() {
+			a.IndexPath(res, path)
+		}
+	}
+
+	return a, nil
+}
+
+// project returns compose project name
+func (c *client) project() (string, error) {
+	if len(c.opts.Project) > 0 {
+		return c.opts.Project, nil
+	}
+
+	path, err := filepath.Abs(c.path)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Base(filepath.Dir(path)), nil
+}
+
+// topology builds compose API topology
+type topology struct {
+	top       *gen.Top
+	ns        string
+	src       api.Source
+	resources map[string]api.Resource
+	objects   map[string]map[string]*gen.Object
+}
+
+// add adds a new object of the given kind to the topology and returns it
+func (t *topology) add(kind, name string, l labels) (*gen.Object, error) {
+	res, ok := t.resources[kind]
+	if !ok {
+		return nil, fmt.Errorf("resource %s: %w", kind, errors.ErrMissingResource)
+	}
+
+	uid := uuid.NewFromString(t.ns + "/" + kind + "/" + name)
+	obj := gen.NewObject(uid, name, t.ns, res, gen.Labels(l), gen.FromSource(t.src))
+
+	if t.objects[kind] == nil {
+		t.objects[kind] = make(map[string]*gen.Object)
+	}
+	t.objects[kind][name] = obj
+	t.top.Add(obj)
+
+	return obj, nil
+}
+
+// link links obj to the object of the given kind and name with relation rel.
+// It returns error if the linked object does not exist.
+func (t *topology) link(obj *gen.Object, kind, name, rel string) error {
+	to, ok := t.objects[kind][name]
+	if !ok {
+		return fmt.Errorf("%s %s: %s %s: %w", obj.Resource().Kind(), obj.Name(), kind, name, errors.ErrUnknownObject)
+	}
+
+	obj.Link(to.UID(), gen.NewRelation(rel))
+
+	return nil
+}
+
+// sortedNames returns the sorted keys of m
+func sortedNames(m map[string]resource) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Map maps the compose file into API topology.
+// It returns error if the file fails to be parsed or if
+// any of the services references an undeclared object.
+func (c *client) Map(a api.API) (api.Top, error) {
+	data, err := ioutil.ReadFile(c.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed reading compose file: %w", err)
+	}
+
+	var p project
+	if err := yaml.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("failed parsing compose file: %w", err)
+	}
+
+	ns, err := c.project()
+	if err != nil {
+		return nil, fmt.Errorf("failed getting project name: %w", err)
+	}
+
+	t := &topology{
+		top:       gen.NewTop(),
+		ns:        ns,
+		src:       a.Source(),
+		resources: make(map[string]api.Resource),
+		objects:   make(map[string]map[string]*gen.Object),
+	}
+
+	for _, res := range a.Resources() {
+		t.resources[res.Kind()] = res
+	}
+
+	for _, decl := range []struct {
+		kind      string
+		resources map[string]resource
+	}{
+		{"network", p.Networks},
+		{"volume", p.Volumes},
+		{"config", p.Configs},
+		{"secret", p.Secrets},
+	} {
+		for _, name := range sortedNames(decl.resources) {
+			if _, err := t.add(decl.kind, name, decl.resources[name].Labels); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	services := make([]string, 0, len(p.Services))
+	for name := range p.Services {
+		services = append(services, name)
+	}
+	sort.Strings(services)
+
+	for _, name := range services {
+		if _, err := t.add("service", name, p.Services[name].Labels); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, name := range services {
+		if err := t.linkService(t.objects["service"][name], p.Services[name]); err != nil {
+			return nil, err
+		}
+	}
+
+	return t.top, nil
+}
+
+// linkService links service object obj to the objects referenced by svc
+func (t *topology) linkService(obj *gen.Object, svc service) error {
+	for _, dep := range svc.DependsOn {
+		if err := t.link(obj, "service", dep, DependsOnRel); err != nil {
+			return err
+		}
+	}
+
+	networks := svc.Networks
+	if len(networks) == 0 {
+		networks = names{defaultNetwork}
+		if _, ok := t.objects["network"][defaultNetwork]; !ok {
+			if _, err := t.add("network", defaultNetwork, nil); err != nil {
+				return err
+			}
+		}
+	}
+
+	exposes := len(svc.Ports) > 0 || len(svc.Expose) > 0
+
+	for _, network := range networks {
+		if err := t.link(obj, "network", network, AttachesToRel); err != nil {
+			return err
+		}
+
+		if exposes {
+			if err := t.link(obj, "network", network, ExposesRel); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, v := range svc.Volumes {
+		// only named volumes are API objects
+		if v.Type != "volume" || len(v.Source) == 0 {
+			continue
+		}
+
+		if err := t.link(obj, "volume", v.Source, MountsRel); err != nil {
+			return err
+		}
+	}
+
+	for _, cfg := range svc.Configs {
+		if err := t.link(obj, "config", cfg.Source, MountsRel); err != nil {
+			return err
+		}
+	}
+
+	for _, secret := range svc.Secrets {
+		if err := t.link(obj, "secret", secret.Source, MountsRel); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,194 @@
+package compose
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/milosgajdos/kraph/pkg/api"
+	kerrors "github.com/milosgajdos/kraph/pkg/errors"
+	"github.com/milosgajdos/kraph/pkg/query"
+)
+
+const (
+	composePath = "seeds/docker-compose.yaml"
+)
+
+// linksOf returns the relations of obj links keyed by the linked object UID
+func linksOf(obj api.Object) map[string][]string {
+	links := make(map[string][]string)
+	for _, l := range obj.Links() {
+		links[l.To().String()] = append(links[l.To().String()], l.Relation().String())
+	}
+
+	for _, rels := range links {
+		sort.Strings(rels)
+	}
+
+	return links
+}
+
+func TestDiscover(t *testing.T) {
+	c := NewClient(composePath)
+
+	a, err := c.Discover()
+	if err != nil {
+		t.Fatalf("failed to discover API: %v", err)
+	}
+
+	if count := len(a.Resources()); count != len(resourceKinds) {
+		t.Errorf("expected resources: %d, got: %d", len(resourceKinds), count)
+	}
+
+	for _, rk := range resourceKinds {
+		res, err := a.Get(query.Build().Kind(rk.kind))
+		if err != nil {
+			t.Errorf("failed to get resource %s: %v", rk.kind, err)
+			continue
+		}
+
+		if len(res) != 1 || res[0].Name() != rk.name {
+			t.Errorf("unexpected resources for kind %s: %v", rk.kind, res)
+		}
+	}
+}
+
+func TestMap(t *testing.T) {
+	c := NewClient(composePath, Project("shop"))
+
+	a, err := c.Discover()
+	if err != nil {
+		t.Fatalf("failed to discover API: %v", err)
+	}
+
+	top, err := c.Map(a)
+	if err != nil {
+		t.Fatalf("failed to map API: %v", err)
+	}
+
+	// 4 services, 3 networks, 1 volume, 1 config, 1 secret
+	if count := len(top.Objects()); count != 10 {
+		t.Errorf("expected objects: %d, got: %d", 10, count)
+	}
+
+	objects := make(map[string]api.Object)
+	for _, obj := range top.Objects() {
+		if obj.Namespace() != "shop" {
+			t.Errorf("expected namespace: %s, got: %s", "shop", obj.Namespace())
+		}
+		if src := obj.Source(); src == nil || src.String() != "compose" {
+			t.Errorf("expected source: %s, got: %v", "compose", src)
+		}
+		objects[obj.UID().String()] = obj
+	}
+
+	testCases := []struct {
+		uid   string
+		links map[string][]string
+	}{
+		{"shop/service/web", map[string][]string{
+			"shop/service/api":      {DependsOnRel},
+			"shop/network/frontend": {AttachesToRel, ExposesRel},
+			"shop/config/nginx":     {MountsRel},
+		}},
+		{"shop/service/api", map[string][]string{
+			"shop/service/db":         {DependsOnRel},
+			"shop/network/frontend":   {AttachesToRel, ExposesRel},
+			"shop/network/backend":    {AttachesToRel, ExposesRel},
+			"shop/secret/db-password": {MountsRel},
+		}},
+		{"shop/service/db", map[string][]string{
+			"shop/network/backend":    {AttachesToRel},
+			"shop/volume/data":        {MountsRel},
+			"shop/secret/db-password": {MountsRel},
+		}},
+		{"shop/service/worker", map[string][]string{
+			"shop/network/default": {AttachesToRel},
+			"shop/volume/data":     {MountsRel},
+		}},
+	}
+
+	for _, tc := range testCases {
+		obj, ok := objects[tc.uid]
+		if !ok {
+			t.Errorf("object %s not found", tc.uid)
+			continue
+		}
+
+		links := linksOf(obj)
+		if len(links) != len(tc.links) {
+			t.Errorf("%s: expected links: %v, got: %v", tc.uid, tc.links, links)
+			continue
+		}
+
+		for to, rels := range tc.links {
+			got := links[to]
+			if len(got) != len(rels) {
+				t.Errorf("%s -> %s: expected relations: %v, got: %v", tc.uid, to, rels, got)
+				continue
+			}
+
+			for i := range rels {
+				if got[i] != rels[i] {
+					t.Errorf("%s -> %s: expected relations: %v, got: %v", tc.uid, to, rels, got)
+				}
+			}
+		}
+	}
+
+	if l := objects["shop/service/web"].Labels()["app"]; l != "web" {
+		t.Errorf("expected label: %s, got: %s", "web", l)
+	}
+
+	if l := objects["shop/service/api"].Labels()["app"]; l != "api" {
+		t.Errorf("expected label: %s, got: %s", "api", l)
+	}
+}
+
+func TestMapProject(t *testing.T) {
+	c := NewClient(composePath)
+
+	a, err := c.Discover()
+	if err != nil {
+		t.Fatalf("failed to discover API: %v", err)
+	}
+
+	top, err := c.Map(a)
+	if err != nil {
+		t.Fatalf("failed to map API: %v", err)
+	}
+
+	for _, obj := range top.Objects() {
+		if obj.Namespace() != "seeds" {
+			t.Errorf("expected namespace: %s, got: %s", "seeds", obj.Namespace())
+		}
+	}
+}
+
+func TestMapUnknownObject(t *testing.T) {
+	dir, err := ioutil.TempDir("", "compose")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "docker-compose.yaml")
+	data := []byte("services:\n  web:\n    image: nginx\n    networks:\n      - missing\n")
+	if err := ioutil.WriteFile(path, data, 0644); err != nil {
+		t.Fatalf("failed to write compose file: %v", err)
+	}
+
+	c := NewClient(path)
+
+	a, err := c.Discover()
+	if err != nil {
+		t.Fatalf("failed to discover API: %v", err)
+	}
+
+	if _, err := c.Map(a); !errors.Is(err, kerrors.ErrUnknownObject) {
+		t.Errorf("expected error: %v, got: %v", kerrors.ErrUnknownObject, err)
+	}
+}
@@ -0,0 +1,145 @@
+package compose
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+// project is a compose file
+type project struct {
+	Services map[string]service  `json:"services"`
+	Networks map[string]resource `json:"networks"`
+	Volumes  map[string]resource `json:"volumes"`
+	Configs  map[string]resource `json:"configs"`
+	Secrets  map[string]resource `json:"secrets"`
+}
+
+// resource is a top level compose resource
+type resource struct {
+	Labels labels `json:"labels,omitempty"`
+}
+
+// UnmarshalJSON decodes compose resource which may be declared without any options
+func (r *resource) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+
+	type raw resource
+	return json.Unmarshal(data, (*raw)(r))
+}
+
+// service is a compose service
+type service struct {
+	Image     string            `json:"image,omitempty"`
+	Labels    labels            `json:"labels,omitempty"`
+	DependsOn names             `json:"depends_on,omitempty"`
+	Networks  names             `json:"networks,omitempty"`
+	Volumes   []volumeMount     `json:"volumes,omitempty"`
+	Configs   []fileMount       `json:"configs,omitempty"`
+	Secrets   []fileMount       `json:"secrets,omitempty"`
+	Ports     []json.RawMessage `json:"ports,omitempty"`
+	Expose    []json.RawMessage `json:"expose,omitempty"`
+}
+
+// names decodes compose lists which are either
+// lists of names or maps keyed by the names
+type names []string
+
+// UnmarshalJSON decodes names
+func (n *names) UnmarshalJSON(data []byte) error {
+	var list []string
+	if err := json.Unmarshal(data, &list); err == nil {
+		*n = list
+		return nil
+	}
+
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(data, &m); err != nil {
+		return err
+	}
+
+	for name := range m {
+		*n = append(*n, name)
+	}
+	sort.Strings(*n)
+
+	return nil
+}
+
+// labels decodes compose labels which are
+// either lists of key=value strings or maps
+type labels map[string]string
+
+// UnmarshalJSON decodes labels
+func (l *labels) UnmarshalJSON(data []byte) error {
+	var list []string
+	if err := json.Unmarshal(data, &list); err == nil {
+		*l = make(labels)
+		for _, kv := range list {
+			parts := strings.SplitN(kv, "=", 2)
+			if len(parts) == 2 {
+				(*l)[parts[0]] = parts[1]
+			} else {
+				(*l)[parts[0]] = ""
+			}
+		}
+		return nil
+	}
+
+	var m map[string]string
+	if err := json.Unmarshal(data, &m); err != nil {
+		return err
+	}
+	*l = m
+
+	return nil
+}
+
+// volumeMount is service volume mount
+type volumeMount struct {
+	Type   string `json:"type"`
+	Source string `json:"source"`
+}
+
+// UnmarshalJSON decodes both short and long volume mount syntax
+func (v *volumeMount) UnmarshalJSON(data []byte) error {
+	var short string
+	if err := json.Unmarshal(data, &short); err != nil {
+		type raw volumeMount
+		return json.Unmarshal(data, (*raw)(v))
+	}
+
+	parts := strings.Split(short, ":")
+	if len(parts) == 1 {
+		// anonymous volume
+		v.Type = "volume"
+		return nil
+	}
+
+	v.Source = parts[0]
+	v.Type = "volume"
+	if strings.HasPrefix(v.Source, "/") ||
+		strings.HasPrefix(v.Source, ".") ||
+		strings.HasPrefix(v.Source, "~") {
+		v.Type = "bind"
+	}
+
+	return nil
+}
+
+// fileMount is service config or secret mount
+type fileMount struct {
+	Source string `json:"source"`
+}
+
+// UnmarshalJSON decodes both short and long file mount syntax
+func (f *fileMount) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &f.Source); err == nil {
+		return nil
+	}
+
+	type raw fileMount
+	return json.Unmarshal(data, (*raw)(f))
+}
@@ -0,0 +1,18 @@
+package compose
+
+// Options are compose client options
+type Options struct {
+	// Project is compose project name.
+	// It defaults to the name of the compose file directory.
+	Project string
+}
+
+// Option is compose client option
+type Option func(*Options)
+
+// Project configures compose project name
+func Project(name string) Option {
+	return func(o *Options) {
+		o.Project = name
+	}
+}
@@ -1,7 +1,10 @@
 package gen
 
 import (
+	"strings"
+
 	"github.com/milosgajdos/kraph/pkg/api"
+	"github.com/milosgajdos/kraph/pkg/errors"
 	"github.com/milosgajdos/kraph/pkg/query"
 )
 
@@ -29,8 +32,10 @@ func (a *API) AddResource(r api.Resource) {
 	a.resources = append(a.resources, r)
 }
 
-// IndexPath indexes resource to given path
+// IndexPath indexes resource to given path.
+// Paths are indexed case insensitively.
 func (a *API) IndexPath(r api.Resource, path string) {
+	path = strings.ToLower(path)
 	a.resourceMap[path] = append(a.resourceMap[path], r)
 }
 
@@ -52,21 +57,76 @@ func (a *API) Resources() []api.Resource {
 	return resources
 }
 
-// Get returns all API resources matching the given query
+// Get returns all API resources matching the given query.
+// It returns error if any of the equality matchers has invalid value.
 func (a *API) Get(q *query.Query) ([]api.Resource, error) {
-	var ar []api.Resource
-
 	match := q.Matcher()
 
-	for _, r := range a.resources {
-		if match.NameVal(r.Name()) {
-			if match.GroupVal(r.Group()) {
-				if match.VersionVal(r.Version()) {
-					ar = append(ar, r)
-				}
+	for _, m := range []struct {
+		query.Matcher
+		err error
+	}{
+		{match.Name(), query.ErrInvalidName},
+		{match.Kind(), query.ErrInvalidKind},
+		{match.Group(), query.ErrInvalidGroup},
+		{match.Version(), query.ErrInvalidVersion},
+	} {
+		if m.IsEq() {
+			if _, ok := m.Value().(string); !ok {
+				return nil, m.err
 			}
 		}
 	}
 
+	var ar []api.Resource
+
+	for _, r := range a.resources {
+		if match.NameVal(r.Name()) &&
+			match.KindVal(r.Kind()) &&
+			match.GroupVal(r.Group()) &&
+			match.VersionVal(r.Version()) {
+			ar = append(ar, r)
+		}
+	}
+
 	return ar, nil
 }
+
+// Lookup returns all API resources indexed to the given name.
+// Besides the indexed paths, name can be given in kubectl style as
+// resource.group or resource.version.group, e.g. ingresses.networking.k8s.io.
+// If no path matches name the resources whose kind is name are returned.
+// It returns errors.ErrUnknownResource if no resource matches name.
+func (a *API) Lookup(name string) ([]api.Resource, error) {
+	name = strings.ToLower(name)
+
+	paths := []string{name}
+	if !strings.Contains(name, "/") {
+		if parts := strings.SplitN(name, ".", 2); len(parts) == 2 {
+			paths = append(paths, parts[0]+"/"+parts[1])
+		}
+
+		if parts := strings.SplitN(name, ".", 3); len(parts) == 3 {
+			paths = append(paths, strings.Join([]string{parts[0], parts[2], parts[1]}, "/"))
+		}
+	}
+
+	for _, path := range paths {
+		if resources, ok := a.resourceMap[path]; ok {
+			return resources, nil
+		}
+	}
+
+	var resources []api.Resource
+	for _, r := range a.resources {
+		if strings.EqualFold(r.Kind(), name) {
+			resources = append(resources, r)
+		}
+	}
+
+	if len(resources) == 0 {
+		return nil, errors.ErrUnknownResource
+	}
+
+	return resources, nil
+}
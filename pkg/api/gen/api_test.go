@@ -1,9 +1,11 @@
 package gen
 
 import (
+	"errors"
 	"strings"
 	"testing"
 
+	kerrors "github.com/milosgajdos/kraph/pkg/errors"
 	"github.com/milosgajdos/kraph/pkg/query"
 )
 
@@ -98,3 +100,82 @@ func TestAPIGet(t *testing.T) {
 		}
 	}
 }
+
+func TestAPIGetMatchFuncs(t *testing.T) {
+	api, err := NewMockAPI(resPath)
+	if err != nil {
+		t.Fatalf("failed to create mock API: %v", err)
+	}
+
+	tests := []struct {
+		q   *query.Query
+		exp int
+	}{
+		{query.Build().Name("foo"), 4},
+		{query.Build().Group("oo", query.StringPrefixFunc("woo")), 2},
+		{query.Build().Name("rnd").Version("v5"), 2},
+		{query.Build().Kind("rndKind").Group("sndGroup"), 3},
+	}
+
+	for i, test := range tests {
+		resources, err := api.Get(test.q)
+		if err != nil {
+			t.Errorf("test %d: failed to get resources: %v", i, err)
+			continue
+		}
+
+		if len(resources) != test.exp {
+			t.Errorf("test %d: expected resources: %d, got: %d", i, test.exp, len(resources))
+		}
+	}
+
+	for _, test := range []struct {
+		q   *query.Query
+		err error
+	}{
+		{query.Build().Name(1), query.ErrInvalidName},
+		{query.Build().Kind(1), query.ErrInvalidKind},
+		{query.Build().Group(1), query.ErrInvalidGroup},
+		{query.Build().Version(1), query.ErrInvalidVersion},
+	} {
+		if _, err := api.Get(test.q); err != test.err {
+			t.Errorf("expected error: %v, got: %v", test.err, err)
+		}
+	}
+}
+
+func TestAPILookup(t *testing.T) {
+	api, err := NewMockAPI(resPath)
+	if err != nil {
+		t.Fatalf("failed to create mock API: %v", err)
+	}
+
+	testCases := []struct {
+		name  string
+		count int
+	}{
+		{"foo", 4},
+		{"fo", 2},
+		{"foo/fooGroup", 2},
+		{"FOO/fooGroup/v1", 1},
+		{"foo.foogroup", 2},
+		{"foo.v2.foogroup", 1},
+		{"barKind", 2},
+	}
+
+	for _, tc := range testCases {
+		resources, err := api.Lookup(tc.name)
+		if err != nil {
+			t.Errorf("failed to lookup %s: %v", tc.name, err)
+			continue
+		}
+
+		if len(resources) != tc.count {
+			t.Errorf("%s: expected resources: %d, got: %d", tc.name, tc.count, len(resources))
+		}
+	}
+
+	if _, err := api.Lookup("unknown"); !errors.Is(err, kerrors.ErrUnknownResource) {
+		t.Errorf("expected error: %v, got: %v", kerrors.ErrUnknownResource, err)
+	}
+}
@@ -24,13 +24,7 @@ func NewMockAPI(resPath string) (api.API, error) {
 	api := NewAPI(resPath)
 
 	for _, r := range resources {
-		m := &Resource{
-			name:       r.Name,
-			kind:       r.Kind,
-			group:      r.Group,
-			version:    r.Version,
-			namespaced: r.Namespaced,
-		}
+		m := newMockResource(r)
 		api.AddResource(m)
 		for _, path := range m.Paths() {
 			api.IndexPath(m, path)
@@ -40,6 +34,18 @@ func NewMockAPI(resPath string) (api.API, error) {
 	return api, nil
 }
 
+// newMockResource returns generic API resource from resource r
+func newMockResource(r types.Resource) *Resource {
+	return NewResource(r.Name, r.Kind, r.Group, r.Version, r.Namespaced,
+		SingularName(r.SingularName),
+		ShortNames(r.ShortNames...),
+		Categories(r.Categories...),
+		Verbs(r.Verbs...),
+		PreferredVersion(r.PreferredVersion),
+		Deprecated(r.Deprecated),
+	)
+}
+
 // NewMockTop returns mock Top from objects and resrouces
 // from given filesystem paths and returns it.
 func NewMockTop(objPath string) (api.Top, error) {
@@ -56,13 +62,7 @@ func NewMockTop(objPath string) (api.Top, error) {
 	top := NewTop()
 
 	for _, o := range objects {
-		r := &Resource{
-			name:       o.Resource.Name,
-			kind:       o.Resource.Kind,
-			group:      o.Resource.Group,
-			version:    o.Resource.Version,
-			namespaced: o.Resource.Namespaced,
-		}
+		r := newMockResource(o.Resource)
 
 		links := make(map[string]api.Link)
 		for _, l := range o.Links {
@@ -80,6 +80,11 @@ func NewMockTop(objPath string) (api.Top, error) {
 			ns:    o.Namespace,
 			res:   r,
 			links: links,
+			opts: ObjectOptions{
+				Labels:      o.Labels,
+				Annotations: o.Annotations,
+				Fields:      o.Fields,
+			},
 		}
 		top.Add(m)
 	}
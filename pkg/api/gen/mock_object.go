@@ -6,6 +6,6 @@ import (
 )
 
 // NewMockObject creates new mock API object and returns it
-func NewMockObject(uid, name, ns string, res api.Resource) api.Object {
-	return NewObject(uuid.NewFromString(uid), name, ns, res)
+func NewMockObject(uid, name, ns string, res api.Resource, opts ...ObjectOption) api.Object {
+	return NewObject(uuid.NewFromString(uid), name, ns, res, opts...)
 }
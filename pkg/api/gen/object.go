@@ -12,16 +12,23 @@ type Object struct {
 	ns    string
 	res   api.Resource
 	links map[string]api.Link
+	opts  ObjectOptions
 }
 
 // NewObject creates a new Object and returns it
-func NewObject(uid uuid.UID, name, ns string, res api.Resource) *Object {
+func NewObject(uid uuid.UID, name, ns string, res api.Resource, opts ...ObjectOption) *Object {
+	objOpts := ObjectOptions{}
+	for _, apply := range opts {
+		apply(&objOpts)
+	}
+
 	return &Object{
 		uid:   uid,
 		res:   res,
 		ns:    ns,
 		name:  name,
 		links: make(map[string]api.Link),
+		opts:  objOpts,
 	}
 }
 
@@ -45,6 +52,26 @@ func (o Object) Resource() api.Resource {
 	return o.res
 }
 
+// Labels returns object labels
+func (o Object) Labels() map[string]string {
+	return o.opts.Labels
+}
+
+// Annotations returns object annotations
+func (o Object) Annotations() map[string]string {
+	return o.opts.Annotations
+}
+
+// Fields returns object fields
+func (o Object) Fields() map[string]string {
+	return o.opts.Fields
+}
+
+// Source returns object API source
+func (o Object) Source() api.Source {
+	return o.opts.Source
+}
+
 // Link links the object to another object
 func (o *Object) Link(to uuid.UID, rel api.Relation) {
 	link := NewLink(o.uid, to, rel)
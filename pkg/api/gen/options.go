@@ -0,0 +1,97 @@
+package gen
+
+import "github.com/milosgajdos/kraph/pkg/api"
+
+// ObjectOptions are generic API object options
+type ObjectOptions struct {
+	Labels      map[string]string
+	Annotations map[string]string
+	Fields      map[string]string
+	Source      api.Source
+}
+
+// ObjectOption configures object
+type ObjectOption func(*ObjectOptions)
+
+// Labels configures object labels
+func Labels(l map[string]string) ObjectOption {
+	return func(o *ObjectOptions) {
+		o.Labels = l
+	}
+}
+
+// Annotations configures object annotations
+func Annotations(a map[string]string) ObjectOption {
+	return func(o *ObjectOptions) {
+		o.Annotations = a
+	}
+}
+
+// Fields configures object fields
+func Fields(f map[string]string) ObjectOption {
+	return func(o *ObjectOptions) {
+		o.Fields = f
+	}
+}
+
+// FromSource configures object API source
+func FromSource(s api.Source) ObjectOption {
+	return func(o *ObjectOptions) {
+		o.Source = s
+	}
+}
+
+// ResourceOptions are generic API resource options
+type ResourceOptions struct {
+	SingularName     string
+	ShortNames       []string
+	Categories       []string
+	Verbs            []string
+	PreferredVersion string
+	Deprecated       bool
+}
+
+// ResourceOption configures resource
+type ResourceOption func(*ResourceOptions)
+
+// SingularName configures resource singular name
+func SingularName(n string) ResourceOption {
+	return func(o *ResourceOptions) {
+		o.SingularName = n
+	}
+}
+
+// ShortNames configures resource short names
+func ShortNames(n ...string) ResourceOption {
+	return func(o *ResourceOptions) {
+		o.ShortNames = n
+	}
+}
+
+// Categories configures resource categories
+func Categories(c ...string) ResourceOption {
+	return func(o *ResourceOptions) {
+		o.Categories = c
+	}
+}
+
+// Verbs configures resource verbs
+func Verbs(v ...string) ResourceOption {
+	return func(o *ResourceOptions) {
+		o.Verbs = v
+	}
+}
+
+// PreferredVersion configures the preferred version of resource group
+func PreferredVersion(v string) ResourceOption {
+	return func(o *ResourceOptions) {
+		o.PreferredVersion = v
+	}
+}
+
+// Deprecated marks resource version as deprecated
+func Deprecated(d bool) ResourceOption {
+	return func(o *ResourceOptions) {
+		o.Deprecated = d
+	}
+}
@@ -9,16 +9,28 @@ type Resource struct {
 	group      string
 	version    string
 	namespaced bool
+	opts       ResourceOptions
+}
+
+// ResourceKey returns the key which identifies the resource of the given kind
+func ResourceKey(group, version, kind string) string {
+	return strings.Join([]string{group, version, kind}, "/")
 }
 
 // NewResrouce returns generic API resource
-func NewResource(name, kind, group, version string, namespaced bool) *Resource {
+func NewResource(name, kind, group, version string, namespaced bool, opts ...ResourceOption) *Resource {
+	ropts := ResourceOptions{}
+	for _, apply := range opts {
+		apply(&ropts)
+	}
+
 	return &Resource{
 		name:       name,
 		kind:       kind,
 		group:      group,
 		version:    version,
 		namespaced: namespaced,
+		opts:       ropts,
 	}
 }
 
@@ -47,12 +59,56 @@ func (r Resource) Namespaced() bool {
 	return r.namespaced
 }
 
+// SingularName returns resource singular name
+func (r Resource) SingularName() string {
+	return r.opts.SingularName
+}
+
+// ShortNames returns resource short names
+func (r Resource) ShortNames() []string {
+	return r.opts.ShortNames
+}
+
+// Categories returns the categories the resource belongs to
+func (r Resource) Categories() []string {
+	return r.opts.Categories
+}
+
+// Verbs returns the verbs supported by the resource
+func (r Resource) Verbs() []string {
+	return r.opts.Verbs
+}
+
+// PreferredVersion returns the preferred version of the resource group.
+// It returns the resource version if no preferred version has been set.
+func (r Resource) PreferredVersion() string {
+	if len(r.opts.PreferredVersion) == 0 {
+		return r.version
+	}
+	return r.opts.PreferredVersion
+}
+
+// Deprecated returns true if the resource version is deprecated
+func (r Resource) Deprecated() bool {
+	return r.opts.Deprecated
+}
+
 // Paths returns all possible variations of the resource paths
 func (r Resource) Paths() []string {
 	resNames := []string{strings.ToLower(r.name)}
+	if len(r.opts.SingularName) > 0 {
+		resNames = append(resNames, strings.ToLower(r.opts.SingularName))
+	}
+	resNames = append(resNames, r.opts.ShortNames...)
 
 	var names []string
+	seen := make(map[string]bool)
 	for _, name := range resNames {
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+
 		names = append(names,
 			name,
 			strings.Join([]string{name, r.group}, "/"),
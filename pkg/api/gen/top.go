@@ -41,120 +41,125 @@ func (t *Top) Add(o api.Object) {
 	}
 }
 
-func (t Top) getNamespaceKindObjects(ns, kind string, q *query.Query) ([]api.Object, error) {
-	var objects []api.Object
+// Objects returns all api objects in the tpoology
+func (t Top) Objects() []api.Object {
+	objects := make([]api.Object, len(t.objects))
 
-	if m := q.Matcher().Name(); m != nil {
-		switch name := m.Value().(type) {
-		case string:
-			if len(name) > 0 {
-				object, ok := t.index[ns][kind][name]
-				if !ok {
-					return objects, nil
-				}
+	i := 0
 
-				objects = append(objects, object)
-			}
-		case query.MatchVal:
-			if name == query.MatchAny {
-				for _, object := range t.index[ns][kind] {
-					objects = append(objects, object)
-				}
-			}
-		}
+	for _, object := range t.objects {
+		objects[i] = object
+		i++
 	}
 
-	return objects, nil
+	return objects
 }
 
-func (t Top) getNamespaceObjects(ns string, q *query.Query) ([]api.Object, error) {
-	var objects []api.Object
-
-	if m := q.Matcher().Kind(); m != nil {
-		switch kind := m.Value().(type) {
-		case string:
-			if len(kind) > 0 {
-				return t.getNamespaceKindObjects(ns, kind, q)
-			}
-		case query.MatchVal:
-			if kind == query.MatchAny {
-				for kind := range t.index[ns] {
-					objs, err := t.getNamespaceKindObjects(ns, kind, q)
-					if err != nil {
-						return nil, err
-					}
-					objects = append(objects, objs...)
-				}
-			}
-		}
+// indexKeys returns the index keys which match m.
+// If m is an equality matcher its value is returned without
+// scanning the index keys. It returns errInvalid if the value
+// of the equality matcher is not a string.
+func indexKeys(m query.Matcher, keys func() []string, errInvalid error) ([]string, error) {
+	if v, ok := m.Value().(query.MatchVal); ok && v == query.MatchAny {
+		return keys(), nil
 	}
 
-	return objects, nil
-}
-
-func (t Top) getAllNamespacedObjects(q *query.Query) ([]api.Object, error) {
-	var objects []api.Object
-
-	for ns := range t.index {
-		objs, err := t.getNamespaceObjects(ns, q)
-		if err != nil {
-			return nil, err
+	if m.IsEq() {
+		key, ok := m.Value().(string)
+		if !ok {
+			return nil, errInvalid
 		}
-		objects = append(objects, objs...)
+		return []string{key}, nil
 	}
 
-	return objects, nil
-}
-
-// Objects returns all api objects in the tpoology
-func (t Top) Objects() []api.Object {
-	objects := make([]api.Object, len(t.objects))
-
-	i := 0
-
-	for _, object := range t.objects {
-		objects[i] = object
-		i++
+	var matched []string
+	for _, k := range keys() {
+		if m.Match(k) {
+			matched = append(matched, k)
+		}
 	}
 
-	return objects
+	return matched, nil
 }
 
-// Get queries the mapped API objects and returns the results
+// Get queries the mapped API objects and returns the results.
+// It returns error if any of the equality matchers has invalid value.
 func (t Top) Get(q *query.Query) ([]api.Object, error) {
 	var objects []api.Object
 
-	if m := q.Matcher().UID(); m != nil {
-		// TODO: when we fail to type-switch, we fall through to MatchAny
-		// Should this logic change? i.e. we can see that UID matcher is NOT nil
-		// but the provided value does not type-switch; maybe we should return
-		// some ErrInvalidUID error or something; this would avoid a lot of missteps
-		if uid, ok := m.Value().(uuid.UID); ok && len(uid.String()) > 0 {
-			if obj, ok := t.objects[uid.String()]; ok {
-				objects = append(objects, obj)
-			}
-			return objects, nil
+	match := q.Matcher()
+
+	if m := match.UID(); m != nil && m.IsEq() {
+		uid, ok := m.Value().(uuid.UID)
+		if !ok {
+			return nil, query.ErrInvalidUID
 		}
-	}
 
-	var ns string
+		if obj, ok := t.objects[uid.String()]; ok && matchObject(match, obj) {
+			objects = append(objects, obj)
+		}
+
+		return objects, nil
+	}
 
-	if m := q.Matcher().Namespace(); m != nil {
-		switch v := m.Value().(type) {
-		case string:
-			ns = v
-		case query.MatchVal:
-			return t.getAllNamespacedObjects(q)
+	namespaces, err := indexKeys(match.Namespace(), func() []string {
+		keys := make([]string, 0, len(t.index))
+		for ns := range t.index {
+			keys = append(keys, ns)
 		}
+		return keys
+	}, query.ErrInvalidNamespace)
+	if err != nil {
+		return nil, err
 	}
 
-	if len(ns) > 0 {
-		objs, err := t.getNamespaceObjects(ns, q)
+	for _, ns := range namespaces {
+		kinds, err := indexKeys(match.Kind(), func() []string {
+			keys := make([]string, 0, len(t.index[ns]))
+			for kind := range t.index[ns] {
+				keys = append(keys, kind)
+			}
+			return keys
+		}, query.ErrInvalidKind)
 		if err != nil {
 			return nil, err
 		}
-		objects = append(objects, objs...)
+
+		for _, kind := range kinds {
+			names, err := indexKeys(match.Name(), func() []string {
+				keys := make([]string, 0, len(t.index[ns][kind]))
+				for name := range t.index[ns][kind] {
+					keys = append(keys, name)
+				}
+				return keys
+			}, query.ErrInvalidName)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, name := range names {
+				if obj, ok := t.index[ns][kind][name]; ok && matchObject(match, obj) {
+					objects = append(objects, obj)
+				}
+			}
+		}
 	}
 
 	return objects, nil
 }
+
+// matchObject returns true if obj matches all the object matchers
+func matchObject(match *query.Match, obj api.Object) bool {
+	var src string
+	if obj.Source() != nil {
+		src = obj.Source().String()
+	}
+
+	return match.UIDVal(obj.UID()) &&
+		match.NamespaceVal(obj.Namespace()) &&
+		match.SourceVal(src) &&
+		match.KindVal(obj.Resource().Kind()) &&
+		match.GroupVal(obj.Resource().Group()) &&
+		match.VersionVal(obj.Resource().Version()) &&
+		match.NameVal(obj.Name())
+}
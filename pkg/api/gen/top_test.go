@@ -135,3 +135,114 @@ func TestTopGet(t *testing.T) {
 		}
 	}
 }
+
+func TestTopGetMatchFuncs(t *testing.T) {
+	top, err := NewMockTop(objPath)
+	if err != nil {
+		t.Fatalf("failed to create mock Top: %v", err)
+	}
+
+	nameRe, err := query.StringRegexFunc("^foo[1-3]$")
+	if err != nil {
+		t.Fatalf("failed to create regex func: %v", err)
+	}
+
+	tests := []struct {
+		q   *query.Query
+		exp int
+	}{
+		{query.Build().Name("foo", nameRe), 3},
+		{query.Build().Namespace("rnd", query.StringPrefixFunc("rnd")), 4},
+		{query.Build().Namespace("fooNs").Kind("fooKind").Name("foo", nameRe), 3},
+		{query.Build().Namespace("fooNs").Kind("fooKind").Name("foo1"), 1},
+		{query.Build().Namespace("fooNs").Name("foo1", query.StringEqFunc("foo1")), 1},
+		{query.Build().Kind("barKind", query.StringInFunc("barKind", "rndKind")), 5},
+		{query.Build().Namespace("nonEx"), 0},
+	}
+
+	for i, test := range tests {
+		objects, err := top.Get(test.q)
+		if err != nil {
+			t.Errorf("test %d: failed to get objects: %v", i, err)
+			continue
+		}
+
+		if len(objects) != test.exp {
+			t.Errorf("test %d: expected objects: %d, got: %d", i, test.exp, len(objects))
+		}
+	}
+}
+
+func TestTopGetInvalid(t *testing.T) {
+	top, err := NewMockTop(objPath)
+	if err != nil {
+		t.Fatalf("failed to create mock Top: %v", err)
+	}
+
+	tests := []struct {
+		q   *query.Query
+		err error
+	}{
+		{query.Build().UID("fooNs/fooKind/foo1"), query.ErrInvalidUID},
+		{query.Build().Namespace(1), query.ErrInvalidNamespace},
+		{query.Build().Kind(1), query.ErrInvalidKind},
+		{query.Build().Name(1), query.ErrInvalidName},
+	}
+
+	for _, test := range tests {
+		if _, err := top.Get(test.q); err != test.err {
+			t.Errorf("expected error: %v, got: %v", test.err, err)
+		}
+	}
+}
+
+func TestTopGetResource(t *testing.T) {
+	top, err := NewMockTop(objPath)
+	if err != nil {
+		t.Fatalf("failed to create mock Top: %v", err)
+	}
+
+	groups := make(map[string]int)
+	versions := make(map[string]int)
+	for _, o := range top.Objects() {
+		groups[o.Resource().Group()]++
+		versions[o.Resource().Version()]++
+	}
+
+	for group, count := range groups {
+		objects, err := top.Get(query.Build().Group(group))
+		if err != nil {
+			t.Fatalf("failed to get group %s objects: %v", group, err)
+		}
+
+		if len(objects) != count {
+			t.Errorf("group %s: expected objects: %d, got: %d", group, count, len(objects))
+		}
+	}
+
+	for version, count := range versions {
+		objects, err := top.Get(query.Build().Version(version))
+		if err != nil {
+			t.Fatalf("failed to get version %s objects: %v", version, err)
+		}
+
+		if len(objects) != count {
+			t.Errorf("version %s: expected objects: %d, got: %d", version, count, len(objects))
+		}
+	}
+
+	for _, q := range []*query.Query{
+		query.Build().Group("nonEx"),
+		query.Build().Version("nonEx"),
+		query.Build().Source("nonEx"),
+	} {
+		objects, err := top.Get(q)
+		if err != nil {
+			t.Fatalf("failed to get objects: %v", err)
+		}
+
+		if len(objects) != 0 {
+			t.Errorf("expected objects: %d, got: %d", 0, len(objects))
+		}
+	}
+}
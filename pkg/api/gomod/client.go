@@ -0,0 +1,348 @@
+package gomod
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+
+	"github.com/milosgajdos/kraph/pkg/api"
+	"github.com/milosgajdos/kraph/pkg/api/gen"
+	"github.com/milosgajdos/kraph/pkg/api/manifest"
+	"github.com/milosgajdos/kraph/pkg/errors"
+	"github.com/milosgajdos/kraph/pkg/uuid"
+)
+
+const (
+	// ModuleKind is Go module kind
+	ModuleKind = "Module"
+	// RequiresRel links modules to the modules they require
+	RequiresRel = "requires"
+	// ReplacesRel links replacement modules to the modules they replace
+	ReplacesRel = "replaces"
+)
+
+// listModule is go list -m -json module
+type listModule struct {
+	Path      string      `json:"Path"`
+	Version   string      `json:"Version,omitempty"`
+	Replace   *listModule `json:"Replace,omitempty"`
+	Main      bool        `json:"Main,omitempty"`
+	Indirect  bool        `json:"Indirect,omitempty"`
+	GoVersion string      `json:"GoVersion,omitempty"`
+}
+
+// module is a module version
+type module struct {
+	version
+	fields map[string]string
+	links  map[string]map[string]bool
+}
+
+// modUID returns the UID of module version v.
+// Versionless modules, like main modules or local replacements, are identified by their path.
+func modUID(v version) string {
+	if len(v.Version) == 0 {
+		return v.Path
+	}
+
+	return v.Path + "@" + v.Version
+}
+
+// graph is a graph of module versions
+type graph struct {
+	modules map[string]*module
+}
+
+// add adds module version v to the graph unless it exists and returns it
+func (g *graph) add(v version) *module {
+	uid := modUID(v)
+
+	m, ok := g.modules[uid]
+	if !ok {
+		m = &module{
+			version: v,
+			fields:  make(map[string]string),
+			links:   make(map[string]map[string]bool),
+		}
+		g.modules[uid] = m
+	}
+
+	return m
+}
+
+// link links module from to module to with relation rel
+func (g *graph) link(from, to *module, rel string) {
+	if from.links[rel] == nil {
+		from.links[rel] = make(map[string]bool)
+	}
+	from.links[rel][modUID(to.version)] = true
+}
+
+// replace links the replacement module to the replaced module.
+// If the replaced module version is not given it links
+// the replacement module to all the versions of the module.
+func (g *graph) replace(r replace) {
+	repl := g.add(r.New)
+
+	if len(r.Old.Version) > 0 {
+		g.link(repl, g.add(r.Old), ReplacesRel)
+		return
+	}
+
+	var replaced []*module
+	for _, m := range g.modules {
+		if m.Path == r.Old.Path && m != repl {
+			replaced = append(replaced, m)
+		}
+	}
+
+	if len(replaced) == 0 {
+		replaced = append(replaced, g.add(r.Old))
+	}
+
+	for _, m := range replaced {
+		g.link(repl, m, ReplacesRel)
+	}
+}
+
+type client struct {
+	// paths are paths to the module files or directories
+	paths []string
+	// g is the module graph loaded by Discover
+	g *graph
+}
+
+// NewClient returns new Go modules API client which reads module dependencies
+// from the go.mod, go.sum and go list -m -json files at paths.
+// Directories are searched for go.mod and go.sum files.
+func NewClient(paths []string) *client {
+	return &client{
+		paths: paths,
+	}
+}
+
+// load reads the module files into module graph
+func (c *client) load() (*graph, error) {
+	files, err := manifest.Files(c.paths, manifest.Base("go.mod", "go.sum"))
+	if err != nil {
+		return nil, fmt.Errorf("failed listing module files: %w", err)
+	}
+
+	g := &graph{
+		modules: make(map[string]*module),
+	}
+
+	var replaces []replace
+
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		switch filepath.Ext(file) {
+		case ".mod":
+			r, err := loadModFile(g, data)
+			if err != nil {
+				return nil, fmt.Errorf("failed parsing %s: %w", file, err)
+			}
+			replaces = append(replaces, r...)
+		case ".sum":
+			if err := loadSumFile(g, data); err != nil {
+				return nil, fmt.Errorf("failed parsing %s: %w", file, err)
+			}
+		default:
+			r, err := loadListFile(g, data)
+			if err != nil {
+				return nil, fmt.Errorf("failed parsing %s: %w", file, err)
+			}
+			replaces = append(replaces, r...)
+		}
+	}
+
+	// replacements are applied once all the module versions are known
+	for _, r := range replaces {
+		g.replace(r)
+	}
+
+	return g, nil
+}
+
+// loadModFile adds the main module and the modules it requires to g.
+// It returns the module replacements.
+func loadModFile(g *graph, data []byte) ([]replace, error) {
+	f, err := parseModFile(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(f.Module) == 0 {
+		return nil, fmt.Errorf("missing module directive")
+	}
+
+	main := g.add(version{Path: f.Module})
+	main.fields["main"] = "true"
+	if len(f.Go) > 0 {
+		main.fields["go"] = f.Go
+	}
+
+	for _, r := range f.Require {
+		m := g.add(r.version)
+		if r.Indirect {
+			m.fields["indirect"] = "true"
+		}
+		g.link(main, m, RequiresRel)
+	}
+
+	return f.Replace, nil
+}
+
+// loadSumFile adds the module versions in go.sum to g
+func loadSumFile(g *graph, data []byte) error {
+	sums, err := parseSumFile(data)
+	if err != nil {
+		return err
+	}
+
+	for _, s := range sums {
+		m := g.add(s.version)
+		if len(s.Hash) > 0 {
+			m.fields["sum"] = s.Hash
+		}
+	}
+
+	return nil
+}
+
+// loadListFile adds the modules in go list -m -json output to g.
+// It returns the module replacements.
+func loadListFile(g *graph, data []byte) ([]replace, error) {
+	var replaces []replace
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+
+	for {
+		var lm listModule
+		if err := dec.Decode(&lm); err != nil {
+			if err == io.EOF {
+				return replaces, nil
+			}
+			return nil, err
+		}
+
+		v := version{Path: lm.Path, Version: lm.Version}
+
+		m := g.add(v)
+		if lm.Main {
+			m.fields["main"] = "true"
+		}
+		if lm.Indirect {
+			m.fields["indirect"] = "true"
+		}
+		if len(lm.GoVersion) > 0 {
+			m.fields["go"] = lm.GoVersion
+		}
+
+		if lm.Replace != nil {
+			replaces = append(replaces, replace{
+				Old: v,
+				New: version{Path: lm.Replace.Path, Version: lm.Replace.Version},
+			})
+		}
+	}
+}
+
+// cached returns the module graph loaded by Discover.
+// It loads the module files if Discover has not been called.
+func (c *client) cached() (*graph, error) {
+	if c.g != nil {
+		return c.g, nil
+	}
+
+	return c.load()
+}
+
+// Discover returns Go modules API.
+// Every module version is an API resource whose group is the module path.
+// The loaded module graph is reused by Map.
+func (c *client) Discover() (api.API, error) {
+	g, err := c.load()
+	if err != nil {
+		return nil, err
+	}
+	c.g = g
+
+	a := gen.NewAPI("gomod")
+
+	for _, uid := range g.uids() {
+		m := g.modules[uid]
+
+		res := gen.NewResource("modules", ModuleKind, m.Path, m.Version, false)
+		a.AddResource(res)
+		for _, path := range res.Paths() {
+			a.IndexPath(res, path)
+		}
+	}
+
+	return a, nil
+}
+
+// uids returns sorted module UIDs
+func (g *graph) uids() []string {
+	uids := make([]string, 0, len(g.modules))
+	for uid := range g.modules {
+		uids = append(uids, uid)
+	}
+	sort.Strings(uids)
+
+	return uids
+}
+
+// Map maps module versions into API topology.
+// The objects are named by the module paths and placed into the global namespace.
+func (c *client) Map(a api.API) (api.Top, error) {
+	g, err := c.cached()
+	if err != nil {
+		return nil, err
+	}
+
+	resources := make(map[string]api.Resource)
+	for _, res := range a.Resources() {
+		resources[modUID(version{Path: res.Group(), Version: res.Version()})] = res
+	}
+
+	src := gen.FromSource(a.Source())
+
+	top := gen.NewTop()
+
+	for _, uid := range g.uids() {
+		m := g.modules[uid]
+
+		res, ok := resources[uid]
+		if !ok {
+			return nil, fmt.Errorf("resource %s: %w", uid, errors.ErrMissingResource)
+		}
+
+		obj := gen.NewObject(uuid.NewFromString(uid), m.Path, api.NsGlobal, res, gen.Fields(m.fields), src)
+
+		for _, rel := range []string{RequiresRel, ReplacesRel} {
+			to := make([]string, 0, len(m.links[rel]))
+			for uid := range m.links[rel] {
+				to = append(to, uid)
+			}
+			sort.Strings(to)
+
+			for _, uid := range to {
+				obj.Link(uuid.NewFromString(uid), gen.NewRelation(rel))
+			}
+		}
+
+		top.Add(obj)
+	}
+
+	return top, nil
+}
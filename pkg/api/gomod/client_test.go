@@ -0,0 +1,199 @@
+package gomod
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/milosgajdos/kraph/pkg/api"
+	"github.com/milosgajdos/kraph/pkg/query"
+)
+
+// mapModules maps the modules found at paths and returns them keyed by their UIDs
+func mapModules(t *testing.T, paths ...string) map[string]api.Object {
+	c := NewClient(paths)
+
+	a, err := c.Discover()
+	if err != nil {
+		t.Fatalf("failed to discover API: %v", err)
+	}
+
+	top, err := c.Map(a)
+	if err != nil {
+		t.Fatalf("failed to map API: %v", err)
+	}
+
+	objects := make(map[string]api.Object)
+	for _, obj := range top.Objects() {
+		if src := obj.Source(); src == nil || src.String() != "gomod" {
+			t.Errorf("%s: expected source: %s, got: %v", obj.UID(), "gomod", src)
+		}
+		objects[obj.UID().String()] = obj
+	}
+
+	return objects
+}
+
+// links returns sorted object links
+func links(obj api.Object) []string {
+	var links []string
+	for _, l := range obj.Links() {
+		links = append(links, l.Relation().String()+" "+l.To().String())
+	}
+	sort.Strings(links)
+
+	return links
+}
+
+func TestDiscover(t *testing.T) {
+	a, err := NewClient([]string{"seeds"}).Discover()
+	if err != nil {
+		t.Fatalf("failed to discover API: %v", err)
+	}
+
+	res, err := a.Get(query.Build().Kind(ModuleKind))
+	if err != nil {
+		t.Fatalf("failed to get resources: %v", err)
+	}
+
+	if count := len(res); count != 8 {
+		t.Errorf("expected resources: %d, got: %d", 8, count)
+	}
+
+	for _, r := range res {
+		if r.Group() == "github.com/pkg/errors" && r.Version() != "v0.9.1" {
+			t.Errorf("expected version: %s, got: %s", "v0.9.1", r.Version())
+		}
+	}
+}
+
+func TestMapModFiles(t *testing.T) {
+	objects := mapModules(t, "seeds")
+
+	if count := len(objects); count != 8 {
+		t.Errorf("expected objects: %d, got: %d", 8, count)
+	}
+
+	testCases := []struct {
+		uid   string
+		links []string
+	}{
+		{"example.com/app", []string{
+			RequiresRel + " example.com/lib@v1.0.0",
+			RequiresRel + " github.com/google/uuid@v1.1.1",
+			RequiresRel + " github.com/pkg/errors@v0.9.1",
+			RequiresRel + " golang.org/x/text@v0.3.2",
+		}},
+		{"../lib", []string{ReplacesRel + " example.com/lib@v1.0.0"}},
+		{"golang.org/x/text@v0.3.3", []string{ReplacesRel + " golang.org/x/text@v0.3.2"}},
+		{"golang.org/x/text@v0.3.0", nil},
+	}
+
+	for _, tc := range testCases {
+		obj, ok := objects[tc.uid]
+		if !ok {
+			t.Errorf("object %s not found", tc.uid)
+			continue
+		}
+
+		got := links(obj)
+		if len(got) != len(tc.links) {
+			t.Errorf("%s: expected links: %v, got: %v", tc.uid, tc.links, got)
+			continue
+		}
+
+		for i := range got {
+			if got[i] != tc.links[i] {
+				t.Errorf("%s: expected links: %v, got: %v", tc.uid, tc.links, got)
+				break
+			}
+		}
+	}
+
+	app := objects["example.com/app"]
+	if app.Fields()["main"] != "true" || app.Fields()["go"] != "1.13" {
+		t.Errorf("unexpected main module fields: %v", app.Fields())
+	}
+
+	text := objects["golang.org/x/text@v0.3.2"]
+	if text.Fields()["indirect"] != "true" {
+		t.Errorf("expected indirect module: %s", text.UID())
+	}
+
+	errs := objects["github.com/pkg/errors@v0.9.1"]
+	if errs.Name() != "github.com/pkg/errors" || errs.Resource().Version() != "v0.9.1" {
+		t.Errorf("unexpected module: %s %s", errs.Name(), errs.Resource().Version())
+	}
+
+	if sum := errs.Fields()["sum"]; sum != "h1:FEBLx1zS214owpjy7qsBeixbURkuhQAwrK5UwLGTwt4=" {
+		t.Errorf("unexpected module sum: %s", sum)
+	}
+}
+
+func TestMapListFile(t *testing.T) {
+	objects := mapModules(t, listPath)
+
+	if count := len(objects); count != 7 {
+		t.Errorf("expected objects: %d, got: %d", 7, count)
+	}
+
+	if app := objects["example.com/app"]; app == nil || app.Fields()["main"] != "true" {
+		t.Errorf("main module not found")
+	}
+
+	for uid, exp := range map[string]string{
+		"../lib":                   ReplacesRel + " example.com/lib@v1.0.0",
+		"golang.org/x/text@v0.3.3": ReplacesRel + " golang.org/x/text@v0.3.2",
+	} {
+		obj, ok := objects[uid]
+		if !ok {
+			t.Errorf("object %s not found", uid)
+			continue
+		}
+
+		if got := links(obj); len(got) != 1 || got[0] != exp {
+			t.Errorf("%s: expected links: %v, got: %v", uid, exp, got)
+		}
+	}
+}
+
+func TestMapDiscovered(t *testing.T) {
+	data, err := ioutil.ReadFile(listPath)
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", listPath, err)
+	}
+
+	dir, err := ioutil.TempDir("", "gomod")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "modules.json")
+	if err := ioutil.WriteFile(path, data, 0644); err != nil {
+		t.Fatalf("failed to write %s: %v", path, err)
+	}
+
+	c := NewClient([]string{path})
+
+	a, err := c.Discover()
+	if err != nil {
+		t.Fatalf("failed to discover API: %v", err)
+	}
+
+	// the modules discovered by Discover are mapped even if the files change
+	if err := ioutil.WriteFile(path, []byte(`{"Path": "example.com/other", "Main": true}`), 0644); err != nil {
+		t.Fatalf("failed to write %s: %v", path, err)
+	}
+
+	top, err := c.Map(a)
+	if err != nil {
+		t.Fatalf("failed to map API: %v", err)
+	}
+
+	if count := len(top.Objects()); count != 7 {
+		t.Errorf("expected objects: %d, got: %d", 7, count)
+	}
+}
@@ -0,0 +1,190 @@
+package gomod
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// version is module version
+type version struct {
+	Path    string
+	Version string
+}
+
+// require is module requirement
+type require struct {
+	version
+	Indirect bool
+}
+
+// replace is module replacement
+type replace struct {
+	Old version
+	New version
+}
+
+// modFile is go.mod file
+type modFile struct {
+	Module  string
+	Go      string
+	Require []require
+	Replace []replace
+}
+
+// unquote unquotes s if it is quoted
+func unquote(s string) string {
+	if strings.HasPrefix(s, `"`) || strings.HasPrefix(s, "`") {
+		if u, err := strconv.Unquote(s); err == nil {
+			return u
+		}
+	}
+
+	return s
+}
+
+// parseModFile parses go.mod file data.
+// Only the module, go, require and replace directives are parsed,
+// all the other directives are ignored.
+func parseModFile(data []byte) (*modFile, error) {
+	f := new(modFile)
+
+	block := ""
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+
+	for n := 1; scanner.Scan(); n++ {
+		line := scanner.Text()
+
+		comment := ""
+		if i := strings.Index(line, "//"); i >= 0 {
+			line, comment = line[:i], strings.TrimSpace(line[i+2:])
+		}
+
+		args := strings.Fields(line)
+		if len(args) == 0 {
+			continue
+		}
+
+		verb := block
+		switch {
+		case len(block) > 0 && args[0] == ")":
+			block = ""
+			continue
+		case len(block) == 0 && len(args) == 2 && args[1] == "(":
+			block = args[0]
+			continue
+		case len(block) == 0:
+			verb, args = args[0], args[1:]
+		}
+
+		for i := range args {
+			args[i] = unquote(args[i])
+		}
+
+		switch verb {
+		case "module":
+			if len(args) != 1 {
+				return nil, fmt.Errorf("line %d: invalid module directive", n)
+			}
+			f.Module = args[0]
+		case "go":
+			if len(args) != 1 {
+				return nil, fmt.Errorf("line %d: invalid go directive", n)
+			}
+			f.Go = args[0]
+		case "require":
+			if len(args) != 2 {
+				return nil, fmt.Errorf("line %d: invalid require directive", n)
+			}
+			f.Require = append(f.Require, require{
+				version:  version{Path: args[0], Version: args[1]},
+				Indirect: comment == "indirect" || strings.HasPrefix(comment, "indirect;"),
+			})
+		case "replace":
+			r, err := parseReplace(args)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", n, err)
+			}
+			f.Replace = append(f.Replace, r)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+// parseReplace parses replace directive arguments: old [version] => new [version]
+func parseReplace(args []string) (replace, error) {
+	var r replace
+
+	i := 0
+	for i < len(args) && args[i] != "=>" {
+		i++
+	}
+
+	old, repl := args[:i], []string{}
+	if i < len(args) {
+		repl = args[i+1:]
+	}
+
+	if len(old) < 1 || len(old) > 2 || len(repl) < 1 || len(repl) > 2 {
+		return r, fmt.Errorf("invalid replace directive")
+	}
+
+	r.Old.Path = old[0]
+	if len(old) == 2 {
+		r.Old.Version = old[1]
+	}
+
+	r.New.Path = repl[0]
+	if len(repl) == 2 {
+		r.New.Version = repl[1]
+	}
+
+	return r, nil
+}
+
+// sum is go.sum entry
+type sum struct {
+	version
+	Hash string
+}
+
+// parseSumFile parses go.sum file data.
+// The hashes of go.mod files are skipped but the module versions they belong to are returned.
+func parseSumFile(data []byte) ([]sum, error) {
+	var sums []sum
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+
+	for n := 1; scanner.Scan(); n++ {
+		args := strings.Fields(scanner.Text())
+		if len(args) == 0 {
+			continue
+		}
+
+		if len(args) != 3 {
+			return nil, fmt.Errorf("line %d: invalid go.sum entry", n)
+		}
+
+		s := sum{version: version{Path: args[0], Version: args[1]}}
+		if strings.HasSuffix(s.Version, "/go.mod") {
+			s.Version = strings.TrimSuffix(s.Version, "/go.mod")
+		} else {
+			s.Hash = args[2]
+		}
+
+		sums = append(sums, s)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return sums, nil
+}
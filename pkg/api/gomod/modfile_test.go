@@ -0,0 +1,80 @@
+package gomod
+
+import (
+	"io/ioutil"
+	"reflect"
+	"testing"
+)
+
+const (
+	modPath  = "seeds/go.mod"
+	sumPath  = "seeds/go.sum"
+	listPath = "seeds/modules.json"
+)
+
+func TestParseModFile(t *testing.T) {
+	data, err := ioutil.ReadFile(modPath)
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", modPath, err)
+	}
+
+	f, err := parseModFile(data)
+	if err != nil {
+		t.Fatalf("failed to parse %s: %v", modPath, err)
+	}
+
+	exp := &modFile{
+		Module: "example.com/app",
+		Go:     "1.13",
+		Require: []require{
+			{version: version{Path: "github.com/pkg/errors", Version: "v0.9.1"}},
+			{version: version{Path: "golang.org/x/text", Version: "v0.3.2"}, Indirect: true},
+			{version: version{Path: "example.com/lib", Version: "v1.0.0"}},
+			{version: version{Path: "github.com/google/uuid", Version: "v1.1.1"}},
+		},
+		Replace: []replace{
+			{Old: version{Path: "example.com/lib"}, New: version{Path: "../lib"}},
+			{Old: version{Path: "golang.org/x/text", Version: "v0.3.2"}, New: version{Path: "golang.org/x/text", Version: "v0.3.3"}},
+		},
+	}
+
+	if !reflect.DeepEqual(f, exp) {
+		t.Errorf("expected mod file: %#v, got: %#v", exp, f)
+	}
+
+	for _, invalid := range []string{
+		"module",
+		"require foo",
+		"replace foo =>",
+		"replace foo v1 bar",
+	} {
+		if _, err := parseModFile([]byte(invalid)); err == nil {
+			t.Errorf("expected error parsing: %s", invalid)
+		}
+	}
+}
+
+func TestParseSumFile(t *testing.T) {
+	data, err := ioutil.ReadFile(sumPath)
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", sumPath, err)
+	}
+
+	sums, err := parseSumFile(data)
+	if err != nil {
+		t.Fatalf("failed to parse %s: %v", sumPath, err)
+	}
+
+	if count := len(sums); count != 7 {
+		t.Errorf("expected sums: %d, got: %d", 7, count)
+	}
+
+	exp := sum{version: version{Path: "golang.org/x/text", Version: "v0.3.0"}}
+	if sums[4] != exp {
+		t.Errorf("expected sum: %v, got: %v", exp, sums[4])
+	}
+
+	if _, err := parseSumFile([]byte("foo v1.0.0")); err == nil {
+		t.Errorf("expected error parsing invalid go.sum")
+	}
+}
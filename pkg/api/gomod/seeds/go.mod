@@ -0,0 +1,21 @@
+module example.com/app
+
+go 1.13
+
+require (
+	github.com/pkg/errors v0.9.1
+	golang.org/x/text v0.3.2 // indirect
+	"example.com/lib" v1.0.0
+)
+
+require github.com/google/uuid v1.1.1
+
+replace example.com/lib => ../lib
+
+replace golang.org/x/text v0.3.2 => golang.org/x/text v0.3.3
+
+exclude github.com/pkg/errors v0.8.0
+
+retract (
+	v1.0.1 // broken
+)
@@ -0,0 +1,265 @@
+package helm
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/milosgajdos/kraph/pkg/api"
+	"github.com/milosgajdos/kraph/pkg/api/gen"
+	"github.com/milosgajdos/kraph/pkg/api/k8s"
+	"github.com/milosgajdos/kraph/pkg/api/manifest"
+	"github.com/milosgajdos/kraph/pkg/errors"
+	"github.com/milosgajdos/kraph/pkg/uuid"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// Group is Helm API group
+	Group = "helm.sh"
+	// Version is Helm API version
+	Version = "v1"
+	// ReleaseKind is Helm release kind
+	ReleaseKind = "Release"
+	// RendersRel links releases to the objects they render
+	RendersRel = "renders"
+	// DependsOnRel links releases to the releases of their chart dependencies
+	DependsOnRel = "dependsOn"
+)
+
+// clusterKinds are the kinds of the well known cluster scoped resources
+var clusterKinds = map[string]bool{
+	"APIService":                     true,
+	"CSIDriver":                      true,
+	"ClusterRole":                    true,
+	"ClusterRoleBinding":             true,
+	"CustomResourceDefinition":       true,
+	"IngressClass":                   true,
+	"MutatingWebhookConfiguration":   true,
+	"Namespace":                      true,
+	"Node":                           true,
+	"PersistentVolume":               true,
+	"PodSecurityPolicy":              true,
+	"PriorityClass":                  true,
+	"RuntimeClass":                   true,
+	"StorageClass":                   true,
+	"ValidatingWebhookConfiguration": true,
+}
+
+type client struct {
+	// paths are paths to release files or directories
+	paths []string
+	// opts are client options
+	opts Options
+}
+
+// NewClient returns new Helm API client which reads Helm releases
+// from the files at paths. Directories are searched for YAML and JSON files.
+func NewClient(paths []string, opts ...Option) *client {
+	copts := Options{
+		Namespace: DefaultNamespace,
+	}
+	for _, apply := range opts {
+		apply(&copts)
+	}
+
+	return &client{
+		paths: paths,
+		opts:  copts,
+	}
+}
+
+// load loads Helm releases from the client paths.
+// Only the latest revision of every release is returned.
+func (c *client) load() ([]*release, error) {
+	files, err := manifest.Files(c.paths, manifest.YAMLOrJSON)
+	if err != nil {
+		return nil, fmt.Errorf("failed listing release files: %w", err)
+	}
+
+	var releases []*release
+	index := make(map[string]int)
+
+	for _, file := range files {
+		rels, err := loadFile(file, c.opts.Namespace)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, rel := range rels {
+			i, ok := index[rel.key()]
+			if !ok {
+				index[rel.key()] = len(releases)
+				releases = append(releases, rel)
+				continue
+			}
+
+			switch existing := releases[i]; {
+			case rel.Version > existing.Version:
+				releases[i] = rel
+			case rel.Version == 0 && existing.Version == 0:
+				// rendered releases may span multiple files
+				existing.objects = append(existing.objects, rel.objects...)
+			}
+		}
+	}
+
+	return releases, nil
+}
+
+// plural returns the plural resource name of kind
+func plural(kind string) string {
+	name := strings.ToLower(kind)
+
+	switch {
+	case strings.HasSuffix(name, "y") && !strings.HasSuffix(name, "ey"):
+		return strings.TrimSuffix(name, "y") + "ies"
+	case strings.HasSuffix(name, "s"), strings.HasSuffix(name, "x"), strings.HasSuffix(name, "ch"):
+		return name + "es"
+	}
+
+	return name + "s"
+}
+
+// Discover returns Helm API.
+// Besides the Helm release resource it returns the resources
+// of all the objects rendered by the releases.
+func (c *client) Discover() (api.API, error) {
+	releases, err := c.load()
+	if err != nil {
+		return nil, err
+	}
+
+	a := gen.NewAPI("helm")
+
+	add := func(res *gen.Resource) {
+		a.AddResource(res)
+		for _, path := range res.Paths() {
+			a.IndexPath(res, path)
+		}
+	}
+
+	add(gen.NewResource("releases", ReleaseKind, Group, Version, true))
+
+	seen := make(map[string]bool)
+
+	for _, rel := range releases {
+		for _, obj := range rel.objects {
+			gv, err := schema.ParseGroupVersion(obj.GetAPIVersion())
+			if err != nil {
+				return nil, fmt.Errorf("failed parsing %s into GroupVersion: %w", obj.GetAPIVersion(), err)
+			}
+
+			key := gen.ResourceKey(gv.Group, gv.Version, obj.GetKind())
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			kind := obj.GetKind()
+			add(gen.NewResource(plural(kind), kind, gv.Group, gv.Version, !clusterKinds[kind]))
+		}
+	}
+
+	return a, nil
+}
+
+// Map maps Helm releases into API topology.
+// Every release is linked to all the objects it renders
+// and to the releases of the charts its chart depends on.
+// The objects are linked to each other the same way as kubernetes API objects.
+func (c *client) Map(a api.API) (api.Top, error) {
+	releases, err := c.load()
+	if err != nil {
+		return nil, err
+	}
+
+	resources := make(map[string]api.Resource)
+	for _, res := range a.Resources() {
+		resources[gen.ResourceKey(res.Group(), res.Version(), res.Kind())] = res
+	}
+
+	relRes, ok := resources[gen.ResourceKey(Group, Version, ReleaseKind)]
+	if !ok {
+		return nil, fmt.Errorf("resource %s: %w", ReleaseKind, errors.ErrMissingResource)
+	}
+
+	// rendered objects are scoped to the API source so they don't collide
+	// with the objects of the same UID discovered in a live cluster
+	src := a.Source()
+
+	top := gen.NewTop()
+
+	relObjs := make([]*gen.Object, len(releases))
+	// charts maps chart names to the releases of the charts
+	charts := make(map[string][]*gen.Object)
+
+	for i, rel := range releases {
+		meta := rel.Chart.Metadata
+
+		fields := map[string]string{
+			"revision": strconv.Itoa(rel.Version),
+		}
+		if len(meta.Name) > 0 {
+			fields["chart"] = meta.Name
+			fields["chartVersion"] = meta.Version
+		}
+
+		uid := uuid.NewFromString("release/" + rel.key())
+		relObj := gen.NewObject(uid, rel.Name, rel.Namespace, relRes, gen.Fields(fields), gen.FromSource(src))
+
+		for _, raw := range rel.objects {
+			gv, err := schema.ParseGroupVersion(raw.GetAPIVersion())
+			if err != nil {
+				return nil, fmt.Errorf("failed parsing %s into GroupVersion: %w", raw.GetAPIVersion(), err)
+			}
+
+			res, ok := resources[gen.ResourceKey(gv.Group, gv.Version, raw.GetKind())]
+			if !ok {
+				return nil, fmt.Errorf("resource %s: %w", raw.GetKind(), errors.ErrMissingResource)
+			}
+
+			obj := k8s.NewObject(res, scope(raw, res, rel.Namespace), nil, src)
+			relObj.Link(obj.UID(), gen.NewRelation(RendersRel))
+			top.Add(obj)
+		}
+
+		relObjs[i] = relObj
+		if len(meta.Name) > 0 {
+			charts[meta.Name] = append(charts[meta.Name], relObj)
+		}
+		top.Add(relObj)
+	}
+
+	for i, rel := range releases {
+		for _, dep := range rel.Chart.Metadata.Dependencies {
+			for _, to := range charts[dep.Name] {
+				if to != relObjs[i] {
+					relObjs[i].Link(to.UID(), gen.NewRelation(DependsOnRel))
+				}
+			}
+		}
+	}
+
+	return top, nil
+}
+
+// scope places the rendered object raw into the release namespace ns
+// unless it has its own namespace and, since rendered objects have no UIDs,
+// it sets its UID to a UID derived from its namespace, kind and name.
+func scope(raw unstructured.Unstructured, res api.Resource, ns string) unstructured.Unstructured {
+	raw = *raw.DeepCopy()
+
+	if res.Namespaced() && len(raw.GetNamespace()) == 0 {
+		raw.SetNamespace(ns)
+	}
+
+	if len(raw.GetUID()) == 0 {
+		uid := strings.Join([]string{raw.GetNamespace(), raw.GetKind(), raw.GetName()}, "/")
+		raw.SetUID(types.UID(strings.ToLower(uid)))
+	}
+
+	return raw
+}
@@ -0,0 +1,146 @@
+package helm
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/milosgajdos/kraph/pkg/api"
+	"github.com/milosgajdos/kraph/pkg/api/k8s"
+	"github.com/milosgajdos/kraph/pkg/query"
+)
+
+const (
+	renderedPath = "seeds/rendered"
+	releasesPath = "seeds/releases"
+)
+
+func TestDiscover(t *testing.T) {
+	c := NewClient([]string{renderedPath, releasesPath})
+
+	a, err := c.Discover()
+	if err != nil {
+		t.Fatalf("failed to discover API: %v", err)
+	}
+
+	testCases := []struct {
+		kind       string
+		name       string
+		namespaced bool
+	}{
+		{ReleaseKind, "releases", true},
+		{"ConfigMap", "configmaps", true},
+		{"Service", "services", true},
+		{"Deployment", "deployments", true},
+		{"ReplicaSet", "replicasets", true},
+		{"StatefulSet", "statefulsets", true},
+		{"ClusterRole", "clusterroles", false},
+	}
+
+	if count := len(a.Resources()); count != len(testCases) {
+		t.Errorf("expected resources: %d, got: %d", len(testCases), count)
+	}
+
+	for _, tc := range testCases {
+		res, err := a.Get(query.Build().Kind(tc.kind))
+		if err != nil {
+			t.Errorf("failed to get resource %s: %v", tc.kind, err)
+			continue
+		}
+
+		if len(res) != 1 || res[0].Name() != tc.name || res[0].Namespaced() != tc.namespaced {
+			t.Errorf("unexpected resources for kind %s: %v", tc.kind, res)
+		}
+	}
+}
+
+func TestMap(t *testing.T) {
+	c := NewClient([]string{renderedPath, releasesPath}, Namespace("dev"))
+
+	a, err := c.Discover()
+	if err != nil {
+		t.Fatalf("failed to discover API: %v", err)
+	}
+
+	top, err := c.Map(a)
+	if err != nil {
+		t.Fatalf("failed to map API: %v", err)
+	}
+
+	objects := make(map[string]api.Object)
+	for _, obj := range top.Objects() {
+		if src := obj.Source(); src == nil || src.String() != "helm" {
+			t.Errorf("%s: expected source: %s, got: %v", obj.UID(), "helm", src)
+		}
+		objects[obj.UID().String()] = obj
+	}
+
+	// 3 releases, 4 web objects, 2 api objects, 2 db objects of the latest revision
+	if count := len(objects); count != 11 {
+		t.Errorf("expected objects: %d, got: %d", 11, count)
+	}
+
+	testCases := []struct {
+		uid   string
+		ns    string
+		links []string
+	}{
+		{"release/dev/web", "dev", []string{
+			RendersRel + " helm//clusterrole/web-reader",
+			RendersRel + " helm/dev/configmap/web-config",
+			RendersRel + " helm/dev/deployment/web",
+			RendersRel + " helm/dev/service/web",
+		}},
+		{"release/prod/api", "prod", []string{
+			DependsOnRel + " release/prod/db",
+			RendersRel + " helm/3b2c1a7e-api",
+			RendersRel + " helm/3b2c1a7e-api-rs",
+		}},
+		{"release/prod/db", "prod", []string{
+			RendersRel + " helm/prod/service/db",
+			RendersRel + " helm/prod/statefulset/db",
+		}},
+		{"helm/3b2c1a7e-api-rs", "prod", []string{
+			k8s.OwnRel + " helm/3b2c1a7e-api",
+		}},
+	}
+
+	for _, tc := range testCases {
+		obj, ok := objects[tc.uid]
+		if !ok {
+			t.Errorf("object %s not found", tc.uid)
+			continue
+		}
+
+		if obj.Namespace() != tc.ns {
+			t.Errorf("%s: expected namespace: %s, got: %s", tc.uid, tc.ns, obj.Namespace())
+		}
+
+		var links []string
+		for _, l := range obj.Links() {
+			links = append(links, l.Relation().String()+" "+l.To().String())
+		}
+		sort.Strings(links)
+
+		if len(links) != len(tc.links) {
+			t.Errorf("%s: expected links: %v, got: %v", tc.uid, tc.links, links)
+			continue
+		}
+
+		for i := range links {
+			if links[i] != tc.links[i] {
+				t.Errorf("%s: expected links: %v, got: %v", tc.uid, tc.links, links)
+				break
+			}
+		}
+	}
+
+	db := objects["release/prod/db"]
+
+	if rev := db.Fields()["revision"]; rev != "2" {
+		t.Errorf("expected revision: %s, got: %s", "2", rev)
+	}
+
+	if chart := db.Fields()["chart"]; chart != "postgresql" {
+		t.Errorf("expected chart: %s, got: %s", "postgresql", chart)
+	}
+}
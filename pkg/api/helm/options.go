@@ -0,0 +1,23 @@
+package helm
+
+const (
+	// DefaultNamespace is the default namespace of the releases rendered by helm template
+	DefaultNamespace = "default"
+)
+
+// Options are Helm client options
+type Options struct {
+	// Namespace is the namespace of the releases rendered by helm template.
+	// It defaults to DefaultNamespace.
+	Namespace string
+}
+
+// Option is Helm client option
+type Option func(*Options)
+
+// Namespace configures the namespace of the releases rendered by helm template
+func Namespace(ns string) Option {
+	return func(o *Options) {
+		o.Namespace = ns
+	}
+}
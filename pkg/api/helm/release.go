@@ -0,0 +1,242 @@
+package helm
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/milosgajdos/kraph/pkg/api/manifest"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// releaseSecretType is the type of Helm release secrets
+	releaseSecretType = "helm.sh/release.v1"
+	// releaseNameAnnotation is the annotation of the release which manages the object
+	releaseNameAnnotation = "meta.helm.sh/release-name"
+	// releaseNsAnnotation is the annotation of the namespace of the release which manages the object
+	releaseNsAnnotation = "meta.helm.sh/release-namespace"
+)
+
+// gzipMagic are the magic bytes of gzip compressed data
+var gzipMagic = []byte{0x1f, 0x8b, 0x08}
+
+// release is Helm release
+type release struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Version   int    `json:"version"`
+	Chart     chart  `json:"chart"`
+	Manifest  string `json:"manifest"`
+	// objects are the objects rendered by the release
+	objects []unstructured.Unstructured
+}
+
+// key returns release key
+func (r *release) key() string {
+	return r.Namespace + "/" + r.Name
+}
+
+// chart is Helm chart
+type chart struct {
+	Metadata struct {
+		Name         string       `json:"name"`
+		Version      string       `json:"version"`
+		AppVersion   string       `json:"appVersion,omitempty"`
+		Dependencies []dependency `json:"dependencies,omitempty"`
+	} `json:"metadata"`
+}
+
+// dependency is Helm chart dependency
+type dependency struct {
+	Name string `json:"name"`
+}
+
+// decodeDocs decodes all YAML or JSON documents read from r.
+// Empty documents are skipped and object lists are expanded.
+func decodeDocs(r io.Reader) ([]map[string]interface{}, error) {
+	var docs []map[string]interface{}
+
+	raw, err := manifest.Decode(r)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, d := range raw {
+		doc, ok := d.(map[string]interface{})
+		if !ok || len(doc) == 0 {
+			continue
+		}
+
+		// expand lists of objects e.g. kubectl get -o yaml output
+		if doc["kind"] == "List" {
+			items, _, _ := unstructured.NestedSlice(doc, "items")
+			for _, item := range items {
+				if obj, ok := item.(map[string]interface{}); ok {
+					docs = append(docs, obj)
+				}
+			}
+			continue
+		}
+
+		docs = append(docs, doc)
+	}
+
+	return docs, nil
+}
+
+// decodeRelease decodes Helm release from JSON data
+// which are optionally base64 encoded and gzip compressed.
+func decodeRelease(data []byte) (*release, error) {
+	if dec, err := base64.StdEncoding.DecodeString(string(data)); err == nil {
+		data = dec
+	}
+
+	if bytes.HasPrefix(data, gzipMagic) {
+		r, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+
+		if data, err = ioutil.ReadAll(r); err != nil {
+			return nil, err
+		}
+	}
+
+	rel := new(release)
+	if err := json.Unmarshal(data, rel); err != nil {
+		return nil, err
+	}
+
+	if err := rel.render(); err != nil {
+		return nil, err
+	}
+
+	return rel, nil
+}
+
+// render decodes release manifest into release objects
+func (r *release) render() error {
+	docs, err := decodeDocs(strings.NewReader(r.Manifest))
+	if err != nil {
+		return fmt.Errorf("release %s: failed decoding manifest: %w", r.key(), err)
+	}
+
+	for _, doc := range docs {
+		r.objects = append(r.objects, unstructured.Unstructured{Object: doc})
+	}
+
+	return nil
+}
+
+// releaseFromSecret decodes Helm release stored in the release secret doc.
+// It returns nil if doc is not a Helm release secret.
+func releaseFromSecret(doc map[string]interface{}) (*release, error) {
+	obj := unstructured.Unstructured{Object: doc}
+
+	if obj.GetKind() != "Secret" {
+		return nil, nil
+	}
+
+	if t, _, _ := unstructured.NestedString(doc, "type"); t != releaseSecretType {
+		return nil, nil
+	}
+
+	data, ok, err := unstructured.NestedString(doc, "data", "release")
+	if err != nil || !ok {
+		return nil, fmt.Errorf("secret %s/%s: missing release data", obj.GetNamespace(), obj.GetName())
+	}
+
+	// secret data are base64 encoded on top of the release encoding
+	raw, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return nil, fmt.Errorf("secret %s/%s: %w", obj.GetNamespace(), obj.GetName(), err)
+	}
+
+	rel, err := decodeRelease(raw)
+	if err != nil {
+		return nil, fmt.Errorf("secret %s/%s: %w", obj.GetNamespace(), obj.GetName(), err)
+	}
+
+	return rel, nil
+}
+
+// loadFile loads Helm releases from the file at path.
+// The file may contain Helm release secrets, decoded Helm releases
+// or manifests rendered by helm template. Rendered objects are assigned
+// to the release given by their Helm annotations or, if they have none,
+// to the release named after the file in the namespace ns.
+func loadFile(path, ns string) ([]*release, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	docs, err := decodeDocs(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed decoding %s: %w", path, err)
+	}
+
+	var releases []*release
+	rendered := make(map[string]*release)
+
+	for _, doc := range docs {
+		rel, err := releaseFromSecret(doc)
+		if err != nil {
+			return nil, err
+		}
+
+		if rel == nil {
+			if _, ok := doc["manifest"]; ok {
+				data, err := json.Marshal(doc)
+				if err != nil {
+					return nil, err
+				}
+
+				if rel, err = decodeRelease(data); err != nil {
+					return nil, fmt.Errorf("failed decoding release in %s: %w", path, err)
+				}
+			}
+		}
+
+		if rel != nil {
+			releases = append(releases, rel)
+			continue
+		}
+
+		obj := unstructured.Unstructured{Object: doc}
+
+		name := obj.GetAnnotations()[releaseNameAnnotation]
+		if len(name) == 0 {
+			base := filepath.Base(path)
+			name = strings.TrimSuffix(base, filepath.Ext(base))
+		}
+
+		relNs := obj.GetAnnotations()[releaseNsAnnotation]
+		if len(relNs) == 0 {
+			relNs = ns
+		}
+
+		key := relNs + "/" + name
+
+		rel, ok := rendered[key]
+		if !ok {
+			rel = &release{Name: name, Namespace: relNs}
+			rendered[key] = rel
+			releases = append(releases, rel)
+		}
+
+		rel.objects = append(rel.objects, obj)
+	}
+
+	return releases, nil
+}
@@ -0,0 +1,13 @@
+package api
+
+import "strings"
+
+// StringIn returns true if s is case insensitively equal to any string in sx
+func StringIn(s string, sx []string) bool {
+	for _, v := range sx {
+		if strings.EqualFold(s, v) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,17 @@
+package api
+
+import "testing"
+
+func TestStringIn(t *testing.T) {
+	if !StringIn("list", []string{"get", "list"}) {
+		t.Errorf("expected to provide list")
+	}
+
+	if !StringIn("Deployment", []string{"deployment"}) {
+		t.Errorf("expected to match case insensitively")
+	}
+
+	if StringIn("list", []string{"get"}) {
+		t.Errorf("expected to NOT provide list")
+	}
+}
@@ -0,0 +1,279 @@
+package inventory
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/milosgajdos/kraph/pkg/api"
+	"github.com/milosgajdos/kraph/pkg/api/gen"
+	"github.com/milosgajdos/kraph/pkg/api/manifest"
+	"github.com/milosgajdos/kraph/pkg/errors"
+	"github.com/milosgajdos/kraph/pkg/uuid"
+)
+
+const (
+	// DefaultSource is the default name of the API source
+	DefaultSource = "inventory"
+)
+
+// object is a mapped object
+type object struct {
+	uid     string
+	name    string
+	ns      string
+	kind    string
+	group   string
+	version string
+	labels  map[string]string
+	fields  map[string]string
+	links   []link
+}
+
+// link is a reference of a mapped object to the objects of kind by their names
+type link struct {
+	names    []string
+	kind     string
+	relation string
+}
+
+type client struct {
+	// mapping are object mapping rules
+	mapping *Mapping
+	// paths are paths to the mapped files or directories
+	paths []string
+}
+
+// NewClient returns new API client which maps the objects found in JSON or YAML
+// files at paths into API objects using the mapping rules. Directories are
+// searched for YAML and JSON files.
+func NewClient(m *Mapping, paths []string) *client {
+	return &client{
+		mapping: m,
+		paths:   paths,
+	}
+}
+
+// selectItems returns the items of the documents selected by the object rule
+func selectItems(r objectRule, docs []interface{}) []interface{} {
+	var items []interface{}
+
+	for _, doc := range docs {
+		if r.sel != nil {
+			items = append(items, r.sel.Values(doc)...)
+			continue
+		}
+
+		if list, ok := doc.([]interface{}); ok {
+			items = append(items, list...)
+			continue
+		}
+
+		items = append(items, doc)
+	}
+
+	return items
+}
+
+// mapObject maps item into object using the object rule
+func mapObject(r objectRule, item interface{}) (object, error) {
+	o := object{
+		name:    r.name.String(item),
+		ns:      r.namespace.String(item),
+		kind:    r.kind.String(item),
+		group:   r.group,
+		version: r.version,
+	}
+
+	if len(o.name) == 0 || len(o.kind) == 0 {
+		return o, fmt.Errorf("missing name or kind: %w", ErrInvalidObject)
+	}
+
+	if len(o.ns) == 0 {
+		o.ns = api.NsGlobal
+	}
+
+	o.uid = r.uid.String(item)
+	if len(o.uid) == 0 {
+		o.uid = strings.Join([]string{o.kind, o.ns, o.name}, "/")
+	}
+
+	for _, v := range r.labels.Values(item) {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		if o.labels == nil {
+			o.labels = make(map[string]string)
+		}
+		for k, l := range m {
+			o.labels[k] = fmt.Sprint(l)
+		}
+	}
+
+	for name, e := range r.fields {
+		if v := e.String(item); len(v) > 0 {
+			if o.fields == nil {
+				o.fields = make(map[string]string)
+			}
+			o.fields[name] = v
+		}
+	}
+
+	for _, l := range r.links {
+		var names []string
+		for _, v := range l.field.Values(item) {
+			switch v := v.(type) {
+			case []interface{}:
+				for _, n := range v {
+					names = append(names, fmt.Sprint(n))
+				}
+			case map[string]interface{}:
+				// maps are not names
+			default:
+				names = append(names, fmt.Sprint(v))
+			}
+		}
+
+		o.links = append(o.links, link{names: names, kind: l.kind, relation: l.relation})
+	}
+
+	return o, nil
+}
+
+// objects maps all the objects found in the client files
+func (c *client) objects() ([]object, error) {
+	rules, err := c.mapping.compile()
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := manifest.Files(c.paths, manifest.YAMLOrJSON)
+	if err != nil {
+		return nil, fmt.Errorf("failed listing files: %w", err)
+	}
+
+	var objects []object
+
+	for _, file := range files {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, err
+		}
+
+		docs, err := manifest.Decode(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed decoding %s: %w", file, err)
+		}
+
+		for i, r := range rules {
+			for j, item := range selectItems(r, docs) {
+				o, err := mapObject(r, item)
+				if err != nil {
+					return nil, fmt.Errorf("%s: object rule %d: item %d: %w", file, i, j, err)
+				}
+				objects = append(objects, o)
+			}
+		}
+	}
+
+	return objects, nil
+}
+
+// source returns the name of the API source
+func (c *client) source() string {
+	if len(c.mapping.Source) == 0 {
+		return DefaultSource
+	}
+
+	return c.mapping.Source
+}
+
+// Discover returns the API of the mapped objects.
+// Every mapped kind is turned into an API resource.
+func (c *client) Discover() (api.API, error) {
+	objects, err := c.objects()
+	if err != nil {
+		return nil, err
+	}
+
+	a := gen.NewAPI(c.source())
+
+	seen := make(map[string]bool)
+
+	for _, o := range objects {
+		key := gen.ResourceKey(o.group, o.version, o.kind)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		res := gen.NewResource(strings.ToLower(o.kind), o.kind, o.group, o.version, true)
+		a.AddResource(res)
+		for _, path := range res.Paths() {
+			a.IndexPath(res, path)
+		}
+	}
+
+	return a, nil
+}
+
+// Map maps the objects into API topology.
+// The referenced objects are looked up in the namespace of the referencing object
+// first and then in the global namespace. Unresolved references are ignored.
+// Object UIDs are scoped to the API source so the objects of
+// different inventories stored in the same store do not collide.
+func (c *client) Map(a api.API) (api.Top, error) {
+	objects, err := c.objects()
+	if err != nil {
+		return nil, err
+	}
+
+	resources := make(map[string]api.Resource)
+	for _, res := range a.Resources() {
+		resources[gen.ResourceKey(res.Group(), res.Version(), res.Kind())] = res
+	}
+
+	src := a.Source()
+
+	top := gen.NewTop()
+
+	// index maps kind/namespace/name to object UIDs
+	index := make(map[string]string)
+	mapped := make([]*gen.Object, len(objects))
+
+	for i, o := range objects {
+		res, ok := resources[gen.ResourceKey(o.group, o.version, o.kind)]
+		if !ok {
+			return nil, fmt.Errorf("resource %s: %w", o.kind, errors.ErrMissingResource)
+		}
+
+		uid := src.String() + "/" + o.uid
+
+		mapped[i] = gen.NewObject(uuid.NewFromString(uid), o.name, o.ns, res,
+			gen.Labels(o.labels),
+			gen.Fields(o.fields),
+			gen.FromSource(src),
+		)
+
+		index[strings.Join([]string{o.kind, o.ns, o.name}, "/")] = uid
+		top.Add(mapped[i])
+	}
+
+	for i, o := range objects {
+		for _, l := range o.links {
+			for _, name := range l.names {
+				for _, ns := range []string{o.ns, api.NsGlobal} {
+					if uid, ok := index[strings.Join([]string{l.kind, ns, name}, "/")]; ok {
+						mapped[i].Link(uuid.NewFromString(uid), gen.NewRelation(l.relation))
+						break
+					}
+				}
+			}
+		}
+	}
+
+	return top, nil
+}
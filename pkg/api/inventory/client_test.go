@@ -0,0 +1,216 @@
+package inventory
+
+import (
+	"errors"
+	"sort"
+	"testing"
+
+	"github.com/milosgajdos/kraph/pkg/api"
+	"github.com/milosgajdos/kraph/pkg/query"
+)
+
+const (
+	mappingPath = "seeds/mapping.yaml"
+	dataPath    = "seeds/cmdb.json"
+)
+
+func TestLoadMapping(t *testing.T) {
+	if _, err := LoadMapping("nonexistent.yaml"); err == nil {
+		t.Errorf("expected error loading nonexistent file")
+	}
+
+	m, err := LoadMapping(mappingPath)
+	if err != nil {
+		t.Fatalf("failed to load mapping: %v", err)
+	}
+
+	if m.Source != "cmdb" {
+		t.Errorf("expected source: %s, got: %s", "cmdb", m.Source)
+	}
+
+	testCases := []*Mapping{
+		{},
+		{Objects: []ObjectRule{{Name: ".name"}}},
+		{Objects: []ObjectRule{{Name: "{.name", Kind: "Foo"}}},
+		{Objects: []ObjectRule{{Name: ".name", Kind: "Foo", Links: []LinkRule{{Field: ".bar"}}}}},
+	}
+
+	for _, tc := range testCases {
+		if _, err := tc.compile(); !errors.Is(err, ErrInvalidMapping) {
+			t.Errorf("expected error: %v, got: %v", ErrInvalidMapping, err)
+		}
+	}
+}
+
+func TestDiscover(t *testing.T) {
+	m, err := LoadMapping(mappingPath)
+	if err != nil {
+		t.Fatalf("failed to load mapping: %v", err)
+	}
+
+	a, err := NewClient(m, []string{dataPath}).Discover()
+	if err != nil {
+		t.Fatalf("failed to discover API: %v", err)
+	}
+
+	if src := a.Source().String(); src != "cmdb" {
+		t.Errorf("expected source: %s, got: %s", "cmdb", src)
+	}
+
+	kinds := []string{"Rack", "Server", "Appliance"}
+
+	if count := len(a.Resources()); count != len(kinds) {
+		t.Errorf("expected resources: %d, got: %d", len(kinds), count)
+	}
+
+	for _, kind := range kinds {
+		res, err := a.Get(query.Build().Kind(kind))
+		if err != nil {
+			t.Errorf("failed to get resource %s: %v", kind, err)
+			continue
+		}
+
+		if len(res) != 1 || res[0].Group() != "cmdb.example.com" || res[0].Version() != "v1" {
+			t.Errorf("unexpected resources for kind %s: %v", kind, res)
+		}
+	}
+}
+
+func TestMap(t *testing.T) {
+	m, err := LoadMapping(mappingPath)
+	if err != nil {
+		t.Fatalf("failed to load mapping: %v", err)
+	}
+
+	c := NewClient(m, []string{dataPath})
+
+	a, err := c.Discover()
+	if err != nil {
+		t.Fatalf("failed to discover API: %v", err)
+	}
+
+	top, err := c.Map(a)
+	if err != nil {
+		t.Fatalf("failed to map API: %v", err)
+	}
+
+	objects := make(map[string]api.Object)
+	for _, obj := range top.Objects() {
+		if src := obj.Source(); src == nil || src.String() != "cmdb" {
+			t.Errorf("%s: expected source: %s, got: %v", obj.UID(), "cmdb", src)
+		}
+		objects[obj.UID().String()] = obj
+	}
+
+	if count := len(objects); count != 6 {
+		t.Errorf("expected objects: %d, got: %d", 6, count)
+	}
+
+	testCases := []struct {
+		uid   string
+		kind  string
+		ns    string
+		links []string
+	}{
+		{"cmdb/Rack/ams/r1", "Rack", "ams", nil},
+		{"cmdb/srv-1000001", "Server", "ams", []string{"locatedIn cmdb/Rack/ams/r1"}},
+		{"cmdb/srv-1000002", "Server", "ams", []string{"dependsOn cmdb/srv-1000001", "locatedIn cmdb/Rack/ams/r2"}},
+		{"cmdb/srv-1000003", "Appliance", "fra", []string{"locatedIn cmdb/Rack/fra/r1"}},
+	}
+
+	for _, tc := range testCases {
+		obj, ok := objects[tc.uid]
+		if !ok {
+			t.Errorf("object %s not found", tc.uid)
+			continue
+		}
+
+		if obj.Resource().Kind() != tc.kind || obj.Namespace() != tc.ns {
+			t.Errorf("%s: unexpected object: %s/%s", tc.uid, obj.Resource().Kind(), obj.Namespace())
+		}
+
+		var links []string
+		for _, l := range obj.Links() {
+			links = append(links, l.Relation().String()+" "+l.To().String())
+		}
+		sort.Strings(links)
+
+		if len(links) != len(tc.links) {
+			t.Errorf("%s: expected links: %v, got: %v", tc.uid, tc.links, links)
+			continue
+		}
+
+		for i := range links {
+			if links[i] != tc.links[i] {
+				t.Errorf("%s: expected links: %v, got: %v", tc.uid, tc.links, links)
+				break
+			}
+		}
+	}
+
+	db := objects["cmdb/srv-1000001"]
+
+	if l := db.Labels()["tier"]; l != "1" {
+		t.Errorf("expected label: %s, got: %s", "1", l)
+	}
+
+	if f := db.Fields()["serial"]; f != "42" {
+		t.Errorf("expected field: %s, got: %s", "42", f)
+	}
+
+	if _, ok := objects["cmdb/srv-1000003"].Fields()["owner"]; ok {
+		t.Errorf("expected missing field owner to be skipped")
+	}
+}
+
+func TestMapSources(t *testing.T) {
+	m, err := LoadMapping(mappingPath)
+	if err != nil {
+		t.Fatalf("failed to load mapping: %v", err)
+	}
+
+	uids := make(map[string]string)
+
+	for _, src := range []string{"", "cmdb"} {
+		mapping := *m
+		mapping.Source = src
+
+		c := NewClient(&mapping, []string{dataPath})
+
+		a, err := c.Discover()
+		if err != nil {
+			t.Fatalf("failed to discover API: %v", err)
+		}
+
+		top, err := c.Map(a)
+		if err != nil {
+			t.Fatalf("failed to map API: %v", err)
+		}
+
+		for _, obj := range top.Objects() {
+			uid := obj.UID().String()
+			if prev, ok := uids[uid]; ok {
+				t.Errorf("%s: object of source %q collides with source %q", uid, src, prev)
+			}
+			uids[uid] = src
+		}
+	}
+
+	if count := len(uids); count != 12 {
+		t.Errorf("expected objects: %d, got: %d", 12, count)
+	}
+
+	if _, ok := uids[DefaultSource+"/srv-1000001"]; !ok {
+		t.Errorf("expected object scoped to the default source")
+	}
+}
+
+func TestMapInvalidObject(t *testing.T) {
+	m := &Mapping{
+		Objects: []ObjectRule{{Select: "{.servers[*]}", Name: ".missing", Kind: "Server"}},
+	}
+
+	if _, err := NewClient(m, []string{dataPath}).Discover(); !errors.Is(err, ErrInvalidObject) {
+		t.Errorf("expected error: %v, got: %v", ErrInvalidObject, err)
+	}
+}
@@ -0,0 +1,236 @@
+package inventory
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"k8s.io/client-go/util/jsonpath"
+)
+
+var (
+	// ErrInvalidMapping is returned when mapping rules are invalid
+	ErrInvalidMapping = errors.New("invalid mapping")
+	// ErrInvalidObject is returned when a mapped object misses its name or kind
+	ErrInvalidObject = errors.New("invalid object")
+)
+
+// Mapping maps objects found in JSON or YAML documents into API objects.
+//
+// All the mapping expressions are JSONPath templates evaluated against the mapped
+// objects: {.hostname} extracts the hostname field, srv-{.id} prefixes the id field
+// and Server is a literal. Expressions starting with a dot are enclosed in curly braces.
+type Mapping struct {
+	// Source is the name of the API source
+	Source string `json:"source"`
+	// Objects are object mapping rules
+	Objects []ObjectRule `json:"objects"`
+}
+
+// ObjectRule maps a set of objects into API objects
+type ObjectRule struct {
+	// Select selects the objects in the documents.
+	// If empty, every document is mapped or if the document
+	// is a list every item of the list is mapped.
+	Select string `json:"select,omitempty"`
+	// UID is the object UID. It defaults to kind/namespace/name.
+	// Mapped object UIDs are prefixed with the name of the API source.
+	UID string `json:"uid,omitempty"`
+	// Name is the object name
+	Name string `json:"name"`
+	// Namespace is the object namespace. It defaults to the global namespace.
+	Namespace string `json:"namespace,omitempty"`
+	// Kind is the object kind
+	Kind string `json:"kind"`
+	// Group is the API group of the objects
+	Group string `json:"group,omitempty"`
+	// Version is the API version of the objects. It defaults to v1.
+	Version string `json:"version,omitempty"`
+	// Labels selects the map of object labels
+	Labels string `json:"labels,omitempty"`
+	// Fields are object fields
+	Fields map[string]string `json:"fields,omitempty"`
+	// Links are object link rules
+	Links []LinkRule `json:"links,omitempty"`
+}
+
+// LinkRule links objects to the objects they reference by name
+type LinkRule struct {
+	// Field selects the names of the referenced objects
+	Field string `json:"field"`
+	// Kind is the kind of the referenced objects
+	Kind string `json:"kind"`
+	// Relation is the link relation
+	Relation string `json:"relation"`
+}
+
+// LoadMapping reads mapping rules from YAML or JSON file and returns them
+func LoadMapping(path string) (*Mapping, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	m := new(Mapping)
+	if err := yaml.Unmarshal(data, m); err != nil {
+		return nil, err
+	}
+
+	if _, err := m.compile(); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// expr is a compiled mapping expression
+type expr struct {
+	path *jsonpath.JSONPath
+}
+
+// compileExpr compiles mapping expression s.
+// It returns nil if s is empty.
+func compileExpr(name, s string) (*expr, error) {
+	if len(s) == 0 {
+		return nil, nil
+	}
+
+	if strings.HasPrefix(s, ".") {
+		s = "{" + s + "}"
+	}
+
+	path := jsonpath.New(name).AllowMissingKeys(true)
+	if err := path.Parse(s); err != nil {
+		return nil, fmt.Errorf("expression %s: %v: %w", name, err, ErrInvalidMapping)
+	}
+
+	return &expr{path: path}, nil
+}
+
+// String evaluates the expression against obj and returns the result as string.
+// It returns empty string if the expression is nil or if it fails to be evaluated.
+func (e *expr) String(obj interface{}) string {
+	if e == nil {
+		return ""
+	}
+
+	var buf bytes.Buffer
+	if err := e.path.Execute(&buf, obj); err != nil {
+		return ""
+	}
+
+	return buf.String()
+}
+
+// Values evaluates the expression against obj and returns all the results
+func (e *expr) Values(obj interface{}) []interface{} {
+	if e == nil {
+		return nil
+	}
+
+	results, err := e.path.FindResults(obj)
+	if err != nil {
+		return nil
+	}
+
+	var values []interface{}
+	for _, result := range results {
+		for _, v := range result {
+			if v.IsValid() && v.CanInterface() {
+				values = append(values, v.Interface())
+			}
+		}
+	}
+
+	return values
+}
+
+// objectRule is a compiled object rule
+type objectRule struct {
+	sel       *expr
+	uid       *expr
+	name      *expr
+	namespace *expr
+	kind      *expr
+	group     string
+	version   string
+	labels    *expr
+	fields    map[string]*expr
+	links     []linkRule
+}
+
+// linkRule is a compiled link rule
+type linkRule struct {
+	field    *expr
+	kind     string
+	relation string
+}
+
+// compile compiles and validates the mapping rules
+func (m *Mapping) compile() ([]objectRule, error) {
+	if len(m.Objects) == 0 {
+		return nil, fmt.Errorf("no object rules: %w", ErrInvalidMapping)
+	}
+
+	rules := make([]objectRule, len(m.Objects))
+
+	for i, o := range m.Objects {
+		if len(o.Name) == 0 || len(o.Kind) == 0 {
+			return nil, fmt.Errorf("object rule %d: missing name or kind: %w", i, ErrInvalidMapping)
+		}
+
+		r := objectRule{
+			group:   o.Group,
+			version: o.Version,
+			fields:  make(map[string]*expr),
+		}
+
+		if len(r.version) == 0 {
+			r.version = "v1"
+		}
+
+		var err error
+		for _, e := range []struct {
+			dst  **expr
+			name string
+			s    string
+		}{
+			{&r.sel, "select", o.Select},
+			{&r.uid, "uid", o.UID},
+			{&r.name, "name", o.Name},
+			{&r.namespace, "namespace", o.Namespace},
+			{&r.kind, "kind", o.Kind},
+			{&r.labels, "labels", o.Labels},
+		} {
+			if *e.dst, err = compileExpr(e.name, e.s); err != nil {
+				return nil, fmt.Errorf("object rule %d: %w", i, err)
+			}
+		}
+
+		for name, f := range o.Fields {
+			if r.fields[name], err = compileExpr(name, f); err != nil {
+				return nil, fmt.Errorf("object rule %d: %w", i, err)
+			}
+		}
+
+		for j, l := range o.Links {
+			if len(l.Field) == 0 || len(l.Kind) == 0 || len(l.Relation) == 0 {
+				return nil, fmt.Errorf("object rule %d: link rule %d: missing field, kind or relation: %w", i, j, ErrInvalidMapping)
+			}
+
+			field, err := compileExpr("link", l.Field)
+			if err != nil {
+				return nil, fmt.Errorf("object rule %d: link rule %d: %w", i, j, err)
+			}
+
+			r.links = append(r.links, linkRule{field: field, kind: l.Kind, relation: l.Relation})
+		}
+
+		rules[i] = r
+	}
+
+	return rules, nil
+}
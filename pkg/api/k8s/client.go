@@ -3,6 +3,8 @@ package k8s
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
 	"sync"
 
 	"github.com/milosgajdos/kraph/pkg/api"
@@ -11,6 +13,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/util/jsonpath"
 )
 
 // API discovery results
@@ -52,48 +55,97 @@ func NewClient(ctx context.Context, disc discovery.DiscoveryInterface, dyn dynam
 	}
 }
 
-// Discover discovers kubernetes API and returns them
+// Discover discovers kubernetes API and returns them.
+// It discovers the preferred versions of API groups unless all versions are configured.
 // It returns error if it fails to read the resources of if it fails to parse their versions
 func (k *client) Discover() (api.API, error) {
-	srvPrefResList, err := k.disc.ServerPreferredResources()
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch API groups: %w", err)
-	}
+	var groups []*metav1.APIGroup
+	var resList []*metav1.APIResourceList
+	var err error
 
-	api := NewAPI("k8s")
+	if k.opts.AllVersions {
+		// all the groups are discovered along with their resources
+		groups, resList, err = k.disc.ServerGroupsAndResources()
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch API groups: %w", err)
+		}
+	} else {
+		resList, err = k.disc.ServerPreferredResources()
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch API groups: %w", err)
+		}
 
-	for _, srvPrefRes := range srvPrefResList {
-		gv, err := schema.ParseGroupVersion(srvPrefRes.GroupVersion)
+		groupList, err := k.disc.ServerGroups()
 		if err != nil {
-			return nil, fmt.Errorf("failed parsing %s into GroupVersion: %w", srvPrefRes.GroupVersion, err)
+			return nil, fmt.Errorf("failed to fetch API groups: %w", err)
 		}
 
-		for _, ar := range srvPrefRes.APIResources {
-			if !stringIn("list", ar.Verbs) {
-				continue
-			}
+		for i := range groupList.Groups {
+			groups = append(groups, &groupList.Groups[i])
+		}
+	}
+
+	preferred := make(map[string]string)
+	for _, group := range groups {
+		preferred[group.Name] = group.PreferredVersion.Version
+	}
+
+	src := "k8s"
+	if len(k.opts.Cluster) > 0 {
+		src = k.opts.Cluster
+	}
 
+	a := NewAPI(src)
+
+	for _, res := range resList {
+		gv, err := schema.ParseGroupVersion(res.GroupVersion)
+		if err != nil {
+			return nil, fmt.Errorf("failed parsing %s into GroupVersion: %w", res.GroupVersion, err)
+		}
+
+		for _, ar := range res.APIResources {
 			resource := Resource{
 				ar: ar,
 				gv: gv,
+				pv: preferred[gv.Group],
+			}
+
+			if isSubresource(resource) {
+				if !k.opts.Subresources {
+					continue
+				}
+			} else if !api.StringIn("list", ar.Verbs) {
+				continue
 			}
 
-			api.AddResource(resource)
+			a.AddResource(resource)
 			for _, path := range resource.Paths() {
-				api.IndexPath(resource, path)
+				a.IndexPath(resource, path)
 			}
 		}
 	}
 
-	return api, nil
+	return a, nil
+}
+
+// versioned is a raw API object served by API resources in multiple versions
+type versioned struct {
+	res      api.Resource
+	raw      unstructured.Unstructured
+	versions []string
 }
 
 // processResults processes API call request results.
 // It builds API topology map from the received results.
-func (k *client) processResults(resChan <-chan result, doneChan chan struct{}, topChan chan<- topMap) {
+// Objects served in multiple API versions are added to the topology only once
+// with the preferred version of their resource. The objects are linked to their
+// subresources which are looked up in subs by the resource paths.
+func (k *client) processResults(resChan <-chan result, doneChan chan struct{}, topChan chan<- topMap,
+	fields map[string]*jsonpath.JSONPath, subs map[string][]api.Resource, src api.Source) {
 	var err error
 
-	top := NewTop()
+	objects := make(map[string]*versioned)
+	var uids []string
 
 	for result := range resChan {
 		if result.err != nil {
@@ -102,9 +154,50 @@ func (k *client) processResults(resChan <-chan result, doneChan chan struct{}, t
 			break
 		}
 
+		gv := schema.GroupVersion{Group: result.apiRes.Group(), Version: result.apiRes.Version()}.String()
+
 		for _, raw := range result.items {
-			object := NewObject(result.apiRes, raw)
-			top.Add(object)
+			uid := string(raw.GetUID())
+			if len(uid) == 0 {
+				uid = strings.ToLower(raw.GetKind() + "-" + raw.GetName())
+			}
+
+			obj, ok := objects[uid]
+			if !ok {
+				objects[uid] = &versioned{res: result.apiRes, raw: raw, versions: []string{gv}}
+				uids = append(uids, uid)
+				continue
+			}
+
+			if !api.StringIn(gv, obj.versions) {
+				obj.versions = append(obj.versions, gv)
+			}
+
+			if preferredOver(result.apiRes, obj.res) {
+				obj.res, obj.raw = result.apiRes, raw
+			}
+		}
+	}
+
+	top := NewTop()
+
+	for _, uid := range uids {
+		obj := objects[uid]
+
+		objFields := extractFields(obj.raw, fields)
+		if k.opts.AllVersions {
+			if objFields == nil {
+				objFields = make(map[string]string)
+			}
+			sort.Strings(obj.versions)
+			objFields[VersionsField] = strings.Join(obj.versions, ",")
+		}
+
+		object := newObject(obj.res, obj.raw, objFields, src)
+		top.Add(object)
+
+		for _, sub := range subs[resourcePath(obj.res)] {
+			top.Add(newSubresource(object, sub))
 		}
 	}
 
@@ -114,16 +207,41 @@ func (k *client) processResults(resChan <-chan result, doneChan chan struct{}, t
 	}
 }
 
+// resourcePath returns the path of the resource res
+// which is the resource name prefixed with its group version.
+// The path of a subresource is the path of its parent resource.
+func resourcePath(res api.Resource) string {
+	name := res.Name()
+	if i := strings.Index(name, "/"); i >= 0 {
+		name = name[:i]
+	}
+
+	return strings.Join([]string{res.Group(), res.Version(), name}, "/")
+}
+
 // Map builds a map of API resources in a given client namespace
 // If the namespace is empty it queries API groups across all namespaces.
 // It returns error if any of the API calls fails with error.
 func (k *client) Map(a api.API) (api.Top, error) {
+	fields, err := parseFields(k.opts.Fields)
+	if err != nil {
+		return nil, err
+	}
+
 	var wg sync.WaitGroup
 
 	resChan := make(chan result, 250)
 	doneChan := make(chan struct{})
 
+	subs := make(map[string][]api.Resource)
+
 	for _, resource := range a.Resources() {
+		// subresources can't be listed; they are mapped with their parent objects
+		if isSubresource(resource) {
+			subs[resourcePath(resource)] = append(subs[resourcePath(resource)], resource)
+			continue
+		}
+
 		// if particular namespace is required and the resource is not namespaced, skip
 		if len(k.opts.Namespace) > 0 && !resource.Namespaced() {
 			continue
@@ -165,8 +283,14 @@ func (k *client) Map(a api.API) (api.Top, error) {
 		}(resource)
 	}
 
+	// objects are scoped to the API source only if the cluster is configured
+	var src api.Source
+	if len(k.opts.Cluster) > 0 {
+		src = a.Source()
+	}
+
 	topChan := make(chan topMap, 1)
-	go k.processResults(resChan, doneChan, topChan)
+	go k.processResults(resChan, doneChan, topChan, fields, subs, src)
 
 	wg.Wait()
 	close(resChan)
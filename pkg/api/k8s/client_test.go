@@ -0,0 +1,134 @@
+package k8s
+
+import (
+	"context"
+	"testing"
+
+	"github.com/milosgajdos/kraph/pkg/api"
+	"github.com/milosgajdos/kraph/pkg/api/gen"
+	"github.com/milosgajdos/kraph/pkg/query"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	fakedisc "k8s.io/client-go/discovery/fake"
+	fakek8s "k8s.io/client-go/testing"
+)
+
+func newRaw(kind, name, uid string) unstructured.Unstructured {
+	return unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"kind": kind,
+			"metadata": map[string]interface{}{
+				"name":      name,
+				"namespace": "default",
+				"uid":       uid,
+			},
+		},
+	}
+}
+
+func TestProcessResults(t *testing.T) {
+	k := NewClient(context.Background(), nil, nil, AllVersions(true), Subresources(true))
+
+	v1 := gen.NewResource("deployments", "Deployment", "apps", "v1", true, gen.PreferredVersion("v1"))
+	v1beta2 := gen.NewResource("deployments", "Deployment", "apps", "v1beta2", true, gen.PreferredVersion("v1"))
+	scale := gen.NewResource("deployments/scale", "Scale", "apps", "v1", true, gen.PreferredVersion("v1"))
+
+	subs := map[string][]api.Resource{
+		resourcePath(scale): {scale},
+	}
+
+	resChan := make(chan result, 2)
+	doneChan := make(chan struct{})
+	topChan := make(chan topMap, 1)
+
+	resChan <- result{apiRes: v1beta2, items: []unstructured.Unstructured{newRaw("Deployment", "foo", "fooUID")}}
+	resChan <- result{apiRes: v1, items: []unstructured.Unstructured{newRaw("Deployment", "foo", "fooUID")}}
+	close(resChan)
+
+	k.processResults(resChan, doneChan, topChan, nil, subs, nil)
+
+	top := <-topChan
+	if top.err != nil {
+		t.Fatalf("failed to process results: %v", top.err)
+	}
+
+	objects := make(map[string]api.Object)
+	for _, obj := range top.top.Objects() {
+		objects[obj.UID().String()] = obj
+	}
+
+	if len(objects) != 2 {
+		t.Fatalf("expected objects: %d, got: %d", 2, len(objects))
+	}
+
+	obj, ok := objects["fooUID"]
+	if !ok {
+		t.Fatalf("object %s not found", "fooUID")
+	}
+
+	if v := obj.Resource().Version(); v != "v1" {
+		t.Errorf("expected version: %s, got: %s", "v1", v)
+	}
+
+	if v := obj.Fields()[VersionsField]; v != "apps/v1,apps/v1beta2" {
+		t.Errorf("expected versions: %s, got: %s", "apps/v1,apps/v1beta2", v)
+	}
+
+	sub, ok := objects["fooUID/scale"]
+	if !ok {
+		t.Fatalf("subresource %s not found", "fooUID/scale")
+	}
+
+	if sub.Name() != "foo/scale" || sub.Namespace() != "default" || sub.Resource().Kind() != "Scale" {
+		t.Errorf("unexpected subresource: %s/%s/%s", sub.Namespace(), sub.Resource().Kind(), sub.Name())
+	}
+
+	links := obj.Links()
+	if len(links) != 1 || links[0].To().String() != "fooUID/scale" || links[0].Relation().String() != SubresourceRel {
+		t.Errorf("expected object to be linked to its subresource, got: %v", links)
+	}
+}
+
+func TestDiscoverAllVersions(t *testing.T) {
+	disc := &fakedisc.FakeDiscovery{Fake: &fakek8s.Fake{}}
+	disc.Resources = []*metav1.APIResourceList{
+		{
+			GroupVersion: "apps/v1",
+			APIResources: []metav1.APIResource{
+				{Name: "deployments", Kind: "Deployment", Namespaced: true, Verbs: []string{"list"}},
+			},
+		},
+		{
+			GroupVersion: "apps/v1beta2",
+			APIResources: []metav1.APIResource{
+				{Name: "deployments", Kind: "Deployment", Namespaced: true, Verbs: []string{"list"}},
+			},
+		},
+	}
+
+	a, err := NewClient(context.Background(), disc, nil, AllVersions(true)).Discover()
+	if err != nil {
+		t.Fatalf("failed to discover API: %v", err)
+	}
+
+	res, err := a.Get(query.Build().Kind("Deployment"))
+	if err != nil {
+		t.Fatalf("failed to get resources: %v", err)
+	}
+
+	if len(res) != 2 {
+		t.Errorf("expected resources: %d, got: %d", 2, len(res))
+	}
+
+	// the groups are discovered along with the resources
+	var groups int
+	for _, action := range disc.Actions() {
+		if action.GetResource().Resource == "group" {
+			groups++
+		}
+	}
+
+	if groups != 1 {
+		t.Errorf("expected group discovery calls: %d, got: %d", 1, groups)
+	}
+}
@@ -1,11 +1,52 @@
 package k8s
 
-// stringIn returns true if string s i in the slice sx
-func stringIn(s string, sx []string) bool {
-	for _, v := range sx {
-		if v == s {
-			return true
+import (
+	"fmt"
+	"strings"
+
+	"github.com/milosgajdos/kraph/pkg/api"
+	"k8s.io/apimachinery/pkg/version"
+	"k8s.io/client-go/util/jsonpath"
+)
+
+// isSubresource returns true if res is a subresource, e.g. pods/log
+func isSubresource(res api.Resource) bool {
+	return strings.Contains(res.Name(), "/")
+}
+
+// preferredOver returns true if resource a is preferred over resource b.
+// Resources in the preferred versions of their groups are preferred over the others,
+// then the more stable and the newer versions are preferred over the others.
+func preferredOver(a, b api.Resource) bool {
+	ap, bp := a.Version() == a.PreferredVersion(), b.Version() == b.PreferredVersion()
+	if ap != bp {
+		return ap
+	}
+
+	if c := version.CompareKubeAwareVersionStrings(a.Version(), b.Version()); c != 0 {
+		return c > 0
+	}
+
+	return a.Group() < b.Group()
+}
+
+// parseFields parses JSONPath expressions of object fields and returns them.
+// Expressions which are not enclosed in curly braces are enclosed before parsing.
+func parseFields(fields map[string]string) (map[string]*jsonpath.JSONPath, error) {
+	paths := make(map[string]*jsonpath.JSONPath)
+
+	for name, expr := range fields {
+		if !strings.HasPrefix(expr, "{") {
+			expr = "{" + expr + "}"
 		}
+
+		path := jsonpath.New(name).AllowMissingKeys(true)
+		if err := path.Parse(expr); err != nil {
+			return nil, fmt.Errorf("failed parsing field %s: %w", name, err)
+		}
+
+		paths[name] = path
 	}
-	return false
+
+	return paths, nil
 }
@@ -1,13 +1,62 @@
 package k8s
 
-import "testing"
+import (
+	"testing"
 
-func TestStringIn(t *testing.T) {
-	if !stringIn("list", []string{"get", "list"}) {
-		t.Errorf("expected to provide list")
+	"github.com/milosgajdos/kraph/pkg/api"
+	"github.com/milosgajdos/kraph/pkg/api/gen"
+)
+
+func TestParseFields(t *testing.T) {
+	fields := map[string]string{
+		"phase":   ".status.phase",
+		"created": "{.metadata.creationTimestamp}",
+	}
+
+	paths, err := parseFields(fields)
+	if err != nil {
+		t.Fatalf("failed to parse fields: %v", err)
+	}
+
+	if len(paths) != len(fields) {
+		t.Errorf("expected paths: %d, got: %d", len(fields), len(paths))
+	}
+
+	if _, err := parseFields(map[string]string{"foo": "{.status[}"}); err == nil {
+		t.Errorf("expected error parsing invalid field")
+	}
+}
+
+func TestPreferredOver(t *testing.T) {
+	v1 := gen.NewResource("deployments", "Deployment", "apps", "v1", true, gen.PreferredVersion("v1"))
+	v1beta1 := gen.NewResource("deployments", "Deployment", "apps", "v1beta1", true, gen.PreferredVersion("v1"))
+	v1beta2 := gen.NewResource("deployments", "Deployment", "apps", "v1beta2", true, gen.PreferredVersion("v1"))
+	ext := gen.NewResource("deployments", "Deployment", "extensions", "v1beta1", true, gen.PreferredVersion("v1beta1"))
+
+	testCases := []struct {
+		a, b     api.Resource
+		expected bool
+	}{
+		{v1, v1beta1, true},
+		{v1beta1, v1, false},
+		{v1beta2, v1beta1, true},
+		{ext, v1beta2, true},
+		{v1, ext, true},
+	}
+
+	for i, tc := range testCases {
+		if res := preferredOver(tc.a, tc.b); res != tc.expected {
+			t.Errorf("test %d: expected: %v, got: %v", i, tc.expected, res)
+		}
+	}
+}
+
+func TestIsSubresource(t *testing.T) {
+	if !isSubresource(gen.NewResource("pods/log", "Pod", "", "v1", true)) {
+		t.Errorf("expected pods/log to be subresource")
 	}
 
-	if stringIn("list", []string{"get"}) {
-		t.Errorf("expected to NOT provide list")
+	if isSubresource(gen.NewResource("pods", "Pod", "", "v1", true)) {
+		t.Errorf("expected pods to NOT be subresource")
 	}
 }
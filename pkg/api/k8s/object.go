@@ -1,17 +1,25 @@
 package k8s
 
 import (
+	"bytes"
 	"strings"
 
 	"github.com/milosgajdos/kraph/pkg/api"
 	"github.com/milosgajdos/kraph/pkg/api/gen"
 	"github.com/milosgajdos/kraph/pkg/uuid"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/util/jsonpath"
 )
 
 const (
 	// OwnRel is k8s api object relation
 	OwnRel = "isOwned"
+	// SubresourceRel links API objects to their subresources
+	SubresourceRel = "hasSubresource"
+	// VersionsField is the object field which lists all API versions serving the object
+	VersionsField = "versions"
+	// lastAppliedConfig is an annotation which stores the whole object; it's not copied
+	lastAppliedConfig = "kubectl.kubernetes.io/last-applied-configuration"
 )
 
 // Object is kubernetes API object
@@ -19,8 +27,15 @@ type Object struct {
 	*gen.Object
 }
 
-// NewObject returns new kubernetes API object
-func NewObject(res api.Resource, raw unstructured.Unstructured) *Object {
+// NewObject returns new kubernetes API object.
+// Object fields are extracted from raw object using the given JSONPath expressions.
+// If src is not nil the UIDs of the object and of its owners are scoped to it.
+func NewObject(res api.Resource, raw unstructured.Unstructured, fields map[string]*jsonpath.JSONPath, src api.Source) *Object {
+	return newObject(res, raw, extractFields(raw, fields), src)
+}
+
+// newObject returns new kubernetes API object with the given fields.
+func newObject(res api.Resource, raw unstructured.Unstructured, fields map[string]string, src api.Source) *Object {
 	name := strings.ToLower(raw.GetName())
 	kind := strings.ToLower(raw.GetKind())
 
@@ -33,16 +48,78 @@ func NewObject(res api.Resource, raw unstructured.Unstructured) *Object {
 	if len(rawUID) == 0 {
 		rawUID = kind + "-" + name
 	}
-	uid := uuid.NewFromString(rawUID)
+	uid := uuid.NewFromString(scopeUID(src, rawUID))
+
+	annotations := raw.GetAnnotations()
+	delete(annotations, lastAppliedConfig)
+
+	opts := []gen.ObjectOption{
+		gen.Labels(raw.GetLabels()),
+		gen.Annotations(annotations),
+		gen.Fields(fields),
+		gen.FromSource(src),
+	}
 
 	obj := &Object{
-		Object: gen.NewObject(uid, name, ns, res),
+		Object: gen.NewObject(uid, name, ns, res, opts...),
 	}
 
 	for _, ref := range raw.GetOwnerReferences() {
 		//fmt.Printf("Object %s/%s/%s/%s owned by %s\n", obj.Resource().Version(), obj.Namespace(), obj.Resource().Kind(), obj.Name(), string(ref.UID))
-		obj.Link(uuid.NewFromString(string(ref.UID)), gen.NewRelation(OwnRel))
+		obj.Link(uuid.NewFromString(scopeUID(src, string(ref.UID))), gen.NewRelation(OwnRel))
 	}
 
 	return obj
 }
+
+// newSubresource returns the subresource res of the object parent
+// and links the parent object to it.
+func newSubresource(parent api.Object, res api.Resource) *Object {
+	sub := res.Name()
+	if i := strings.Index(sub, "/"); i >= 0 {
+		sub = sub[i+1:]
+	}
+
+	uid := uuid.NewFromString(parent.UID().String() + "/" + sub)
+
+	obj := &Object{
+		Object: gen.NewObject(uid, parent.Name()+"/"+sub, parent.Namespace(), res, gen.FromSource(parent.Source())),
+	}
+
+	parent.Link(uid, gen.NewRelation(SubresourceRel))
+
+	return obj
+}
+
+// scopeUID scopes uid to API source src.
+// It returns uid unchanged if src is nil.
+func scopeUID(src api.Source, uid string) string {
+	if src == nil {
+		return uid
+	}
+
+	return src.String() + "/" + uid
+}
+
+// extractFields extracts fields from raw object and returns them.
+// Fields which are missing in the raw object are skipped.
+func extractFields(raw unstructured.Unstructured, fields map[string]*jsonpath.JSONPath) map[string]string {
+	if len(fields) == 0 {
+		return nil
+	}
+
+	values := make(map[string]string)
+
+	for name, path := range fields {
+		var buf bytes.Buffer
+		if err := path.Execute(&buf, raw.Object); err != nil {
+			continue
+		}
+
+		if buf.Len() > 0 {
+			values[name] = buf.String()
+		}
+	}
+
+	return values
+}
@@ -0,0 +1,101 @@
+package k8s
+
+import (
+	"testing"
+
+	"github.com/milosgajdos/kraph/pkg/api/gen"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestNewObject(t *testing.T) {
+	raw := unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"kind": "Pod",
+			"metadata": map[string]interface{}{
+				"name":      "Foo",
+				"namespace": "Bar",
+				"uid":       "fooUID",
+				"labels": map[string]interface{}{
+					"app": "foo",
+				},
+				"annotations": map[string]interface{}{
+					"foo":             "bar",
+					lastAppliedConfig: "{}",
+				},
+				"ownerReferences": []interface{}{
+					map[string]interface{}{
+						"apiVersion": "apps/v1",
+						"kind":       "ReplicaSet",
+						"name":       "foo",
+						"uid":        "ownerUID",
+					},
+				},
+			},
+			"status": map[string]interface{}{
+				"phase": "Running",
+			},
+		},
+	}
+
+	fields, err := parseFields(map[string]string{
+		"phase": ".status.phase",
+		"node":  ".spec.nodeName",
+	})
+	if err != nil {
+		t.Fatalf("failed to parse fields: %v", err)
+	}
+
+	res := gen.NewResource("pods", "Pod", "", "v1", true)
+
+	obj := NewObject(res, raw, fields, nil)
+
+	if obj.Name() != "foo" || obj.Namespace() != "bar" || obj.UID().String() != "fooUID" {
+		t.Errorf("unexpected object: %s/%s/%s", obj.Namespace(), obj.Name(), obj.UID())
+	}
+
+	if l := obj.Labels()["app"]; l != "foo" {
+		t.Errorf("expected label: %s, got: %s", "foo", l)
+	}
+
+	if _, ok := obj.Annotations()[lastAppliedConfig]; ok {
+		t.Errorf("unexpected annotation: %s", lastAppliedConfig)
+	}
+
+	if a := obj.Annotations()["foo"]; a != "bar" {
+		t.Errorf("expected annotation: %s, got: %s", "bar", a)
+	}
+
+	if p := obj.Fields()["phase"]; p != "Running" {
+		t.Errorf("expected phase: %s, got: %s", "Running", p)
+	}
+
+	if _, ok := obj.Fields()["node"]; ok {
+		t.Errorf("expected missing field node to be skipped")
+	}
+
+	if count := len(obj.Links()); count != 1 {
+		t.Errorf("expected links: %d, got: %d", 1, count)
+	}
+
+	if obj.Source() != nil {
+		t.Errorf("expected nil source, got: %v", obj.Source())
+	}
+
+	src := NewSource("prod")
+
+	obj = NewObject(res, raw, nil, src)
+
+	if uid := obj.UID().String(); uid != "prod/fooUID" {
+		t.Errorf("expected uid: %s, got: %s", "prod/fooUID", uid)
+	}
+
+	if obj.Source() != src {
+		t.Errorf("expected source: %v, got: %v", src, obj.Source())
+	}
+
+	for _, link := range obj.Links() {
+		if to := link.To().String(); to != "prod/ownerUID" {
+			t.Errorf("expected link to: %s, got: %s", "prod/ownerUID", to)
+		}
+	}
+}
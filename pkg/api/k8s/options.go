@@ -3,6 +3,18 @@ package k8s
 // Options provides k8so options
 type Options struct {
 	Namespace string
+	// Cluster is the name of the cluster.
+	// When set, it is the API source of all the objects
+	// and it scopes the object UIDs to avoid collisions.
+	Cluster string
+	// Fields maps field names to JSONPath
+	// expressions which extract them from objects
+	Fields map[string]string
+	// AllVersions discovers the resources of all served
+	// group versions instead of just the preferred ones
+	AllVersions bool
+	// Subresources maps the subresources of API objects
+	Subresources bool
 }
 
 // Option is k8s option
@@ -14,3 +26,31 @@ func Namespace(ns string) Option {
 		o.Namespace = ns
 	}
 }
+
+// Cluster configures cluster name
+func Cluster(name string) Option {
+	return func(o *Options) {
+		o.Cluster = name
+	}
+}
+
+// Fields configures JSONPath expressions of extracted object fields
+func Fields(f map[string]string) Option {
+	return func(o *Options) {
+		o.Fields = f
+	}
+}
+
+// AllVersions configures discovery of all served API group versions
+func AllVersions(all bool) Option {
+	return func(o *Options) {
+		o.AllVersions = all
+	}
+}
+
+// Subresources configures mapping of object subresources
+func Subresources(sub bool) Option {
+	return func(o *Options) {
+		o.Subresources = sub
+	}
+}
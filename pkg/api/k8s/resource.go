@@ -11,6 +11,8 @@ import (
 type Resource struct {
 	ar metav1.APIResource
 	gv schema.GroupVersion
+	// pv is the preferred version of the resource group
+	pv string
 }
 
 // Name returns the name of the resource
@@ -38,19 +40,61 @@ func (r Resource) Namespaced() bool {
 	return r.ar.Namespaced
 }
 
+// SingularName returns resource singular name.
+// It returns lowercase kind if the API server does not report singular name.
+func (r Resource) SingularName() string {
+	if len(r.ar.SingularName) == 0 {
+		return strings.ToLower(r.ar.Kind)
+	}
+	return r.ar.SingularName
+}
+
+// ShortNames returns resource short names
+func (r Resource) ShortNames() []string {
+	return r.ar.ShortNames
+}
+
+// Categories returns the categories the resource belongs to
+func (r Resource) Categories() []string {
+	return r.ar.Categories
+}
+
+// Verbs returns the verbs supported by the resource
+func (r Resource) Verbs() []string {
+	return r.ar.Verbs
+}
+
+// PreferredVersion returns the preferred version of the resource group
+func (r Resource) PreferredVersion() string {
+	if len(r.pv) == 0 {
+		return r.gv.Version
+	}
+	return r.pv
+}
+
+// Deprecated returns true if the resource version is deprecated.
+// Kubernetes discovery API does not report deprecated resources, so it always returns false.
+func (r Resource) Deprecated() bool {
+	return false
+}
+
 // Paths returns all possible variations of the resource paths
 func (r Resource) Paths() []string {
-	// WTF: SingularName is often an empty string!
-	// TODO: figure this out; but for now let's set it to Kind
-	singularName := r.ar.SingularName
-	if len(singularName) == 0 {
-		singularName = r.ar.Kind
+	resNames := []string{strings.ToLower(r.ar.Name)}
+	// subresources are only indexed by their full names, e.g. pods/log
+	if !isSubresource(r) {
+		resNames = append(resNames, strings.ToLower(r.SingularName()))
+		resNames = append(resNames, r.ar.ShortNames...)
 	}
-	resNames := []string{strings.ToLower(r.ar.Name), strings.ToLower(singularName)}
-	resNames = append(resNames, r.ar.ShortNames...)
 
 	var names []string
+	seen := make(map[string]bool)
 	for _, name := range resNames {
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+
 		names = append(names,
 			name,
 			strings.Join([]string{name, r.gv.Group}, "/"),
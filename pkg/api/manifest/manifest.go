@@ -0,0 +1,102 @@
+package manifest
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+
+	kyaml "k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// MatchFunc returns true if the file at path should be read
+type MatchFunc func(path string) bool
+
+// Ext returns MatchFunc which matches the files with any of the extensions
+func Ext(exts ...string) MatchFunc {
+	return func(path string) bool {
+		for _, ext := range exts {
+			if filepath.Ext(path) == ext {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Base returns MatchFunc which matches the files with any of the base names
+func Base(names ...string) MatchFunc {
+	return func(path string) bool {
+		for _, name := range names {
+			if filepath.Base(path) == name {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// YAMLOrJSON matches YAML and JSON files
+var YAMLOrJSON = Ext(".yaml", ".yml", ".json")
+
+// Files walks the given paths and returns the files matched by match.
+// Explicitly requested files are returned regardless of match.
+func Files(paths []string, match MatchFunc) ([]string, error) {
+	var files []string
+
+	for _, path := range paths {
+		err := filepath.Walk(path, func(p string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if info.IsDir() {
+				return nil
+			}
+
+			if p == path || match(p) {
+				files = append(files, p)
+			}
+
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return files, nil
+}
+
+// Decode decodes all YAML or JSON documents read from r.
+// Empty documents are skipped.
+// JSON numbers are decoded as json.Number so they keep their formatting.
+func Decode(r io.Reader) ([]interface{}, error) {
+	var docs []interface{}
+
+	dec := kyaml.NewYAMLOrJSONDecoder(r, 4096)
+
+	for {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			if err == io.EOF {
+				return docs, nil
+			}
+			return nil, err
+		}
+
+		if len(raw) == 0 || string(raw) == "null" {
+			continue
+		}
+
+		var doc interface{}
+		d := json.NewDecoder(bytes.NewReader(raw))
+		d.UseNumber()
+		if err := d.Decode(&doc); err != nil {
+			return nil, err
+		}
+
+		docs = append(docs, doc)
+	}
+}
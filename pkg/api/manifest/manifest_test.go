@@ -0,0 +1,94 @@
+package manifest
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "manifest")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	names := []string{"a.yaml", "b.json", "c.txt", "sub/d.yml", "sub/go.mod"}
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create dir: %v", err)
+		}
+		if err := ioutil.WriteFile(path, nil, 0644); err != nil {
+			t.Fatalf("failed to write file: %v", err)
+		}
+	}
+
+	tests := []struct {
+		paths []string
+		match MatchFunc
+		exp   []string
+	}{
+		{[]string{dir}, YAMLOrJSON, []string{"a.yaml", "b.json", "sub/d.yml"}},
+		{[]string{dir}, Base("go.mod"), []string{"sub/go.mod"}},
+		{[]string{filepath.Join(dir, "c.txt")}, YAMLOrJSON, []string{"c.txt"}},
+	}
+
+	for _, test := range tests {
+		files, err := Files(test.paths, test.match)
+		if err != nil {
+			t.Fatalf("failed to find files: %v", err)
+		}
+
+		var rel []string
+		for _, f := range files {
+			r, err := filepath.Rel(dir, f)
+			if err != nil {
+				t.Fatalf("failed to get relative path: %v", err)
+			}
+			rel = append(rel, filepath.ToSlash(r))
+		}
+		sort.Strings(rel)
+
+		if !reflect.DeepEqual(rel, test.exp) {
+			t.Errorf("expected files: %v, got: %v", test.exp, rel)
+		}
+	}
+
+	if _, err := Files([]string{filepath.Join(dir, "nonEx")}, YAMLOrJSON); err == nil {
+		t.Errorf("expected error for missing path")
+	}
+}
+
+func TestDecode(t *testing.T) {
+	data := `
+kind: Foo
+size: 10
+---
+---
+- name: bar
+`
+
+	docs, err := Decode(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("failed to decode documents: %v", err)
+	}
+
+	exp := []interface{}{
+		map[string]interface{}{"kind": "Foo", "size": json.Number("10")},
+		[]interface{}{map[string]interface{}{"name": "bar"}},
+	}
+
+	if !reflect.DeepEqual(docs, exp) {
+		t.Errorf("expected documents: %#v, got: %#v", exp, docs)
+	}
+
+	if _, err := Decode(strings.NewReader("{invalid")); err == nil {
+		t.Errorf("expected decoding error")
+	}
+}
@@ -0,0 +1,223 @@
+package openapi
+
+import (
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"github.com/milosgajdos/kraph/pkg/api"
+	"github.com/milosgajdos/kraph/pkg/api/gen"
+	"github.com/milosgajdos/kraph/pkg/errors"
+	"github.com/milosgajdos/kraph/pkg/uuid"
+	"k8s.io/client-go/discovery"
+)
+
+const (
+	// Group is OpenAPI schema API group
+	Group = "openapi"
+	// Version is OpenAPI schema API version
+	Version = "v1"
+	// KindKind is the kind of the schema definitions of API resources
+	KindKind = "Kind"
+	// TypeKind is the kind of the schema definitions of all the other types
+	TypeKind = "Type"
+	// HasFieldRel links schema definitions to the types of their fields
+	HasFieldRel = "hasField"
+	// ReferencesRel links API resource definitions to the definitions of the resources they reference
+	ReferencesRel = "references"
+)
+
+// Loader loads OpenAPI document
+type Loader func() ([]byte, error)
+
+// FileLoader returns Loader which loads OpenAPI v2 or v3 document
+// or custom resource definitions from the file at path.
+func FileLoader(path string) Loader {
+	return func() ([]byte, error) {
+		return ioutil.ReadFile(path)
+	}
+}
+
+// DiscoveryLoader returns Loader which loads OpenAPI v2 document from kubernetes API server
+func DiscoveryLoader(disc discovery.DiscoveryInterface) Loader {
+	return func() ([]byte, error) {
+		return disc.RESTClient().Get().
+			AbsPath("/openapi/v2").
+			SetHeader("Accept", "application/json").
+			Do().
+			Raw()
+	}
+}
+
+type client struct {
+	// load loads OpenAPI document
+	load Loader
+}
+
+// NewClient returns new OpenAPI schema client which builds the graph
+// of the schema definitions loaded from the OpenAPI document.
+func NewClient(load Loader) *client {
+	return &client{
+		load: load,
+	}
+}
+
+// definitions loads and parses schema definitions
+func (c *client) definitions() (definitions, error) {
+	data, err := c.load()
+	if err != nil {
+		return nil, fmt.Errorf("failed loading OpenAPI document: %w", err)
+	}
+
+	defs, err := parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed parsing OpenAPI document: %w", err)
+	}
+
+	return defs, nil
+}
+
+// References returns the fields of API resources which likely reference
+// objects of other kinds by their names. The fields can be extracted from
+// API objects and turned into relationships between the objects.
+func (c *client) References() ([]Reference, error) {
+	defs, err := c.definitions()
+	if err != nil {
+		return nil, err
+	}
+
+	return defs.references(), nil
+}
+
+// Discover returns OpenAPI schema API
+func (c *client) Discover() (api.API, error) {
+	a := gen.NewAPI("openapi")
+
+	for _, res := range []*gen.Resource{
+		gen.NewResource("kinds", KindKind, Group, Version, true),
+		gen.NewResource("types", TypeKind, Group, Version, true),
+	} {
+		a.AddResource(res)
+		for _, path := range res.Paths() {
+			a.IndexPath(res, path)
+		}
+	}
+
+	return a, nil
+}
+
+// fieldTypes returns sorted names of the definitions referenced by the fields of schema s
+// including the fields of its inline object, array and map fields.
+func fieldTypes(s *schema) []string {
+	refs := make(map[string]bool)
+
+	var collect func(s *schema, depth int)
+	collect = func(s *schema, depth int) {
+		if depth >= maxDepth {
+			return
+		}
+
+		for _, fs := range s.Properties {
+			fs = fs.elem()
+			if ref := fs.ref(); len(ref) > 0 {
+				refs[ref] = true
+				continue
+			}
+			collect(fs, depth+1)
+		}
+	}
+	collect(s, 0)
+
+	names := make([]string, 0, len(refs))
+	for ref := range refs {
+		names = append(names, ref)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Map maps schema definitions into API topology.
+// Definitions are placed into the namespaces given by their name prefix
+// e.g. io.k8s.api.core.v1.Pod is named Pod and placed into io.k8s.api.core.v1.
+// Definitions are linked to the definitions of their field types and the API resource
+// definitions are linked to the definitions of the resources their fields reference.
+func (c *client) Map(a api.API) (api.Top, error) {
+	defs, err := c.definitions()
+	if err != nil {
+		return nil, err
+	}
+
+	resources := make(map[string]api.Resource)
+	for _, res := range a.Resources() {
+		resources[res.Kind()] = res
+	}
+
+	for _, kind := range []string{KindKind, TypeKind} {
+		if _, ok := resources[kind]; !ok {
+			return nil, fmt.Errorf("resource %s: %w", kind, errors.ErrMissingResource)
+		}
+	}
+
+	src := gen.FromSource(a.Source())
+
+	top := gen.NewTop()
+
+	objects := make(map[string]*gen.Object)
+	// kinds maps API resource kinds to the definitions of their versions
+	kinds := make(map[string][]*gen.Object)
+
+	for _, name := range defs.names() {
+		s := defs[name]
+
+		res := resources[TypeKind]
+		var fields map[string]string
+
+		if len(s.GVK) > 0 {
+			res = resources[KindKind]
+			fields = map[string]string{
+				"group":   s.GVK[0].Group,
+				"version": s.GVK[0].Version,
+				"kind":    s.GVK[0].Kind,
+			}
+		}
+
+		ns, short := api.NsGlobal, name
+		if i := strings.LastIndex(name, "."); i >= 0 {
+			ns, short = name[:i], name[i+1:]
+		}
+		obj := gen.NewObject(uuid.NewFromString(name), short, ns, res, gen.Fields(fields), src)
+
+		for _, ref := range fieldTypes(s) {
+			if _, ok := defs[ref]; ok {
+				obj.Link(uuid.NewFromString(ref), gen.NewRelation(HasFieldRel))
+			}
+		}
+
+		for _, k := range s.GVK {
+			kinds[k.Kind] = append(kinds[k.Kind], obj)
+		}
+
+		objects[name] = obj
+		top.Add(obj)
+	}
+
+	linked := make(map[string]bool)
+
+	for _, ref := range defs.references() {
+		from := objects[ref.def]
+
+		for _, to := range kinds[ref.To] {
+			key := ref.def + "/" + to.UID().String()
+			if linked[key] {
+				continue
+			}
+			linked[key] = true
+
+			from.Link(to.UID(), gen.NewRelation(ReferencesRel))
+		}
+	}
+
+	return top, nil
+}
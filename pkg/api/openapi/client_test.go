@@ -0,0 +1,126 @@
+package openapi
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/milosgajdos/kraph/pkg/api"
+)
+
+const (
+	swaggerPath = "seeds/swagger.json"
+	v3Path      = "seeds/openapi-v3.json"
+	crdPath     = "seeds/crd.yaml"
+)
+
+func TestReferences(t *testing.T) {
+	testCases := []struct {
+		path string
+		refs []Reference
+	}{
+		{swaggerPath, []Reference{
+			{Version: "v1", Kind: "Pod", Path: ".spec.containers[*].env[*].valueFrom.configMapKeyRef.name", Field: "configMapKeyRef", To: "ConfigMap"},
+			{Version: "v1", Kind: "Pod", Path: ".spec.nodeName", Field: "nodeName", To: "Node"},
+			{Version: "v1", Kind: "Pod", Path: ".spec.serviceAccountName", Field: "serviceAccountName", To: "ServiceAccount"},
+		}},
+		{v3Path, []Reference{
+			{Group: "apps", Version: "v1", Kind: "Deployment", Path: ".spec.template.spec.priorityClassName", Field: "priorityClassName", To: "PriorityClass"},
+		}},
+		{crdPath, []Reference{
+			{Group: "example.com", Version: "v1", Kind: "Backup", Path: ".spec.databaseRef.name", Field: "databaseRef", To: "Database"},
+		}},
+	}
+
+	for _, tc := range testCases {
+		refs, err := NewClient(FileLoader(tc.path)).References()
+		if err != nil {
+			t.Errorf("%s: failed to get references: %v", tc.path, err)
+			continue
+		}
+
+		if len(refs) != len(tc.refs) {
+			t.Errorf("%s: expected references: %v, got: %v", tc.path, tc.refs, refs)
+			continue
+		}
+
+		for i := range refs {
+			refs[i].def = ""
+			if refs[i] != tc.refs[i] {
+				t.Errorf("%s: expected reference: %v, got: %v", tc.path, tc.refs[i], refs[i])
+			}
+		}
+	}
+}
+
+func TestMap(t *testing.T) {
+	c := NewClient(FileLoader(swaggerPath))
+
+	a, err := c.Discover()
+	if err != nil {
+		t.Fatalf("failed to discover API: %v", err)
+	}
+
+	top, err := c.Map(a)
+	if err != nil {
+		t.Fatalf("failed to map API: %v", err)
+	}
+
+	objects := make(map[string]api.Object)
+	for _, obj := range top.Objects() {
+		if src := obj.Source(); src == nil || src.String() != "openapi" {
+			t.Errorf("%s: expected source: %s, got: %v", obj.UID(), "openapi", src)
+		}
+		objects[obj.UID().String()] = obj
+	}
+
+	if count := len(objects); count != 10 {
+		t.Errorf("expected objects: %d, got: %d", 10, count)
+	}
+
+	pod, ok := objects["io.k8s.api.core.v1.Pod"]
+	if !ok {
+		t.Fatalf("pod definition not found")
+	}
+
+	if pod.Name() != "Pod" || pod.Namespace() != "io.k8s.api.core.v1" || pod.Resource().Kind() != KindKind {
+		t.Errorf("unexpected pod definition: %s/%s/%s", pod.Resource().Kind(), pod.Namespace(), pod.Name())
+	}
+
+	if kind := pod.Fields()["kind"]; kind != "Pod" {
+		t.Errorf("expected kind field: %s, got: %s", "Pod", kind)
+	}
+
+	var links []string
+	for _, l := range pod.Links() {
+		links = append(links, l.Relation().String()+" "+l.To().String())
+	}
+	sort.Strings(links)
+
+	exp := []string{
+		HasFieldRel + " io.k8s.api.core.v1.PodSpec",
+		HasFieldRel + " io.k8s.apimachinery.pkg.apis.meta.v1.ObjectMeta",
+		ReferencesRel + " io.k8s.api.core.v1.ConfigMap",
+		ReferencesRel + " io.k8s.api.core.v1.Node",
+		ReferencesRel + " io.k8s.api.core.v1.ServiceAccount",
+	}
+
+	if len(links) != len(exp) {
+		t.Fatalf("expected links: %v, got: %v", exp, links)
+	}
+
+	for i := range links {
+		if links[i] != exp[i] {
+			t.Errorf("expected links: %v, got: %v", exp, links)
+			break
+		}
+	}
+
+	spec := objects["io.k8s.api.core.v1.EnvVar"]
+	if spec.Resource().Kind() != TypeKind {
+		t.Errorf("expected kind: %s, got: %s", TypeKind, spec.Resource().Kind())
+	}
+
+	if count := len(spec.Links()); count != 1 {
+		t.Errorf("expected links: %d, got: %d", 1, count)
+	}
+}
@@ -0,0 +1,151 @@
+package openapi
+
+import (
+	"sort"
+	"strings"
+)
+
+const (
+	// maxDepth is the maximum depth of the walked schema fields
+	maxDepth = 16
+)
+
+// Reference is a field of API resource which references objects of another kind by name
+type Reference struct {
+	// Group is the group of the referencing resource
+	Group string
+	// Version is the version of the referencing resource
+	Version string
+	// Kind is the kind of the referencing resource
+	Kind string
+	// Path is JSONPath expression of the field which holds the name of the referenced object
+	Path string
+	// Field is the name of the referencing field
+	Field string
+	// To is the kind of the referenced objects
+	To string
+	// def is the name of the referencing resource definition
+	def string
+}
+
+// kinds returns the kinds of the resource definitions indexed by their lowercase names
+func (d definitions) kinds() map[string]string {
+	kinds := make(map[string]string)
+
+	for _, s := range d {
+		for _, k := range s.GVK {
+			kinds[strings.ToLower(k.Kind)] = k.Kind
+		}
+	}
+
+	return kinds
+}
+
+// refBase returns the base name of a reference field and the path to the name
+// of the referenced object relative to the field. The fields named *Name hold
+// the names directly while the fields named *Ref and *KeyRef are objects
+// which hold the names in their name field. It returns empty string
+// if the field does not look like a reference field.
+func (d definitions) refBase(field string, s *schema) (string, string) {
+	switch {
+	case strings.HasSuffix(field, "Name") && s.Type == "string":
+		return strings.TrimSuffix(field, "Name"), ""
+	case strings.HasSuffix(field, "Ref"):
+		props := s.Properties
+		if ref := s.ref(); len(ref) > 0 && d[ref] != nil {
+			props = d[ref].Properties
+		}
+
+		if _, ok := props["name"]; !ok {
+			return "", ""
+		}
+
+		base := strings.TrimSuffix(field, "KeyRef")
+		if base == field {
+			base = strings.TrimSuffix(field, "Ref")
+		}
+
+		return base, ".name"
+	}
+
+	return "", ""
+}
+
+// references returns the fields of the API resources in the schema definitions
+// which likely reference objects of other kinds. The referenced kind is guessed
+// from the name of the field e.g. serviceAccountName references ServiceAccount
+// and configMapKeyRef references ConfigMap.
+func (d definitions) references() []Reference {
+	kinds := d.kinds()
+
+	var refs []Reference
+
+	for _, name := range d.names() {
+		s := d[name]
+
+		for _, k := range s.GVK {
+			w := &walker{defs: d, kinds: kinds, def: name, gvk: k}
+			w.walk(s, "", map[string]bool{name: true}, 0)
+			refs = append(refs, w.refs...)
+		}
+	}
+
+	return refs
+}
+
+// walker walks resource schema fields collecting references
+type walker struct {
+	defs  definitions
+	kinds map[string]string
+	def   string
+	gvk   gvk
+	refs  []Reference
+}
+
+// walk walks the properties of schema s found at path
+func (w *walker) walk(s *schema, path string, visited map[string]bool, depth int) {
+	if depth >= maxDepth {
+		return
+	}
+
+	fields := make([]string, 0, len(s.Properties))
+	for field := range s.Properties {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	for _, field := range fields {
+		fs := s.Properties[field]
+		fieldPath := path + "." + field
+
+		if fs.Items != nil {
+			fs = fs.Items
+			fieldPath += "[*]"
+		}
+
+		if base, suffix := w.defs.refBase(field, fs); len(base) > 0 {
+			if kind, ok := w.kinds[strings.ToLower(base)]; ok {
+				w.refs = append(w.refs, Reference{
+					Group:   w.gvk.Group,
+					Version: w.gvk.Version,
+					Kind:    w.gvk.Kind,
+					Path:    fieldPath + suffix,
+					Field:   field,
+					To:      kind,
+					def:     w.def,
+				})
+			}
+		}
+
+		if ref := fs.ref(); len(ref) > 0 {
+			if rs, ok := w.defs[ref]; ok && !visited[ref] {
+				visited[ref] = true
+				w.walk(rs, fieldPath, visited, depth+1)
+				delete(visited, ref)
+			}
+			continue
+		}
+
+		w.walk(fs, fieldPath, visited, depth+1)
+	}
+}
@@ -0,0 +1,221 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	kyaml "k8s.io/apimachinery/pkg/util/yaml"
+)
+
+const (
+	// crdKind is the kind of custom resource definitions
+	crdKind = "CustomResourceDefinition"
+)
+
+// gvk is kubernetes group, version and kind
+type gvk struct {
+	Group   string `json:"group"`
+	Version string `json:"version"`
+	Kind    string `json:"kind"`
+}
+
+// schema is OpenAPI schema
+type schema struct {
+	Type                 string             `json:"type,omitempty"`
+	Ref                  string             `json:"$ref,omitempty"`
+	AllOf                []*schema          `json:"allOf,omitempty"`
+	Items                *schema            `json:"items,omitempty"`
+	Properties           map[string]*schema `json:"properties,omitempty"`
+	AdditionalProperties json.RawMessage    `json:"additionalProperties,omitempty"`
+	GVK                  []gvk              `json:"x-kubernetes-group-version-kind,omitempty"`
+}
+
+// ref returns the name of the definition referenced by the schema.
+// It returns empty string if the schema does not reference any definition.
+func (s *schema) ref() string {
+	ref := s.Ref
+	if len(ref) == 0 && len(s.AllOf) == 1 {
+		ref = s.AllOf[0].Ref
+	}
+
+	return ref[strings.LastIndex(ref, "/")+1:]
+}
+
+// additional returns the schema of additional properties.
+// It returns nil if the additional properties are not described by a schema.
+func (s *schema) additional() *schema {
+	if !bytes.HasPrefix(bytes.TrimSpace(s.AdditionalProperties), []byte("{")) {
+		return nil
+	}
+
+	a := new(schema)
+	if err := json.Unmarshal(s.AdditionalProperties, a); err != nil {
+		return nil
+	}
+
+	return a
+}
+
+// elem returns the schema of the elements of array and map schemas.
+// It returns s if s is neither an array nor a map.
+func (s *schema) elem() *schema {
+	if s.Items != nil {
+		return s.Items
+	}
+
+	if a := s.additional(); a != nil {
+		return a
+	}
+
+	return s
+}
+
+// document is OpenAPI v2 or v3 document
+type document struct {
+	Definitions map[string]*schema `json:"definitions,omitempty"`
+	Components  struct {
+		Schemas map[string]*schema `json:"schemas,omitempty"`
+	} `json:"components,omitempty"`
+}
+
+// definitions are named OpenAPI schemas
+type definitions map[string]*schema
+
+// names returns sorted definition names
+func (d definitions) names() []string {
+	names := make([]string, 0, len(d))
+	for name := range d {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// parse parses OpenAPI v2 or v3 document or a set of kubernetes
+// custom resource definitions and returns the schema definitions.
+func parse(data []byte) (definitions, error) {
+	var doc document
+	if err := yaml.Unmarshal(data, &doc); err == nil {
+		switch {
+		case len(doc.Definitions) > 0:
+			return doc.Definitions, nil
+		case len(doc.Components.Schemas) > 0:
+			return doc.Components.Schemas, nil
+		}
+	}
+
+	defs, err := parseCRDs(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(defs) == 0 {
+		return nil, fmt.Errorf("no schema definitions found")
+	}
+
+	return defs, nil
+}
+
+// parseCRDs parses the schemas of custom resource definitions.
+// Every served version of a custom resource is turned into a separate definition.
+func parseCRDs(data []byte) (definitions, error) {
+	defs := make(definitions)
+
+	dec := kyaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), 4096)
+
+	for {
+		var doc map[string]interface{}
+		if err := dec.Decode(&doc); err != nil {
+			if err == io.EOF {
+				return defs, nil
+			}
+			return nil, err
+		}
+
+		docs := []map[string]interface{}{doc}
+		if doc["kind"] == "List" {
+			docs = nil
+			items, _, _ := unstructured.NestedSlice(doc, "items")
+			for _, item := range items {
+				if obj, ok := item.(map[string]interface{}); ok {
+					docs = append(docs, obj)
+				}
+			}
+		}
+
+		for _, doc := range docs {
+			if doc["kind"] != crdKind {
+				continue
+			}
+
+			if err := addCRD(defs, doc); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+// crd is kubernetes custom resource definition
+type crd struct {
+	Spec struct {
+		Group string `json:"group"`
+		Names struct {
+			Kind string `json:"kind"`
+		} `json:"names"`
+		Validation *struct {
+			Schema *schema `json:"openAPIV3Schema"`
+		} `json:"validation,omitempty"`
+		Versions []struct {
+			Name   string `json:"name"`
+			Served bool   `json:"served"`
+			Schema *struct {
+				Schema *schema `json:"openAPIV3Schema"`
+			} `json:"schema,omitempty"`
+		} `json:"versions"`
+	} `json:"spec"`
+}
+
+// addCRD adds the schemas of custom resource definition doc to defs
+func addCRD(defs definitions, doc map[string]interface{}) error {
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+
+	var c crd
+	if err := json.Unmarshal(data, &c); err != nil {
+		return err
+	}
+
+	for _, v := range c.Spec.Versions {
+		if !v.Served {
+			continue
+		}
+
+		var s *schema
+		switch {
+		case v.Schema != nil && v.Schema.Schema != nil:
+			s = v.Schema.Schema
+		case c.Spec.Validation != nil && c.Spec.Validation.Schema != nil:
+			// apiextensions.k8s.io/v1beta1 CRDs share the schema between versions
+			s = c.Spec.Validation.Schema
+		default:
+			s = &schema{Type: "object"}
+		}
+
+		def := *s
+		def.GVK = []gvk{{Group: c.Spec.Group, Version: v.Name, Kind: c.Spec.Names.Kind}}
+
+		name := strings.Join([]string{c.Spec.Group, v.Name, c.Spec.Names.Kind}, ".")
+		defs[name] = &def
+	}
+
+	return nil
+}
@@ -0,0 +1,213 @@
+package terraform
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/milosgajdos/kraph/pkg/api"
+	"github.com/milosgajdos/kraph/pkg/api/gen"
+	"github.com/milosgajdos/kraph/pkg/errors"
+	"github.com/milosgajdos/kraph/pkg/uuid"
+)
+
+const (
+	// Group is the API group of Terraform modules
+	Group = "terraform"
+	// Version is Terraform API version
+	Version = "v4"
+	// ModuleKind is Terraform module kind
+	ModuleKind = "module"
+	// DependsOnRel links resource instances to the instances they depend on
+	DependsOnRel = "dependsOn"
+	// MemberOfRel links resource instances and modules to their parent modules
+	MemberOfRel = "memberOf"
+)
+
+type client struct {
+	// path is state file path
+	path string
+	// s is the state loaded by Discover
+	s *state
+}
+
+// NewClient returns new Terraform API client for the state file at path
+func NewClient(path string) *client {
+	return &client{
+		path: path,
+	}
+}
+
+// load reads and parses the state file
+func (c *client) load() (*state, error) {
+	data, err := ioutil.ReadFile(c.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed reading state file: %w", err)
+	}
+
+	s := new(state)
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("failed parsing state file: %w", err)
+	}
+
+	if s.Version != stateVersion {
+		return nil, fmt.Errorf("unsupported state version: %d", s.Version)
+	}
+
+	return s, nil
+}
+
+// cached returns the state loaded by Discover.
+// It loads the state file if Discover has not been called.
+func (c *client) cached() (*state, error) {
+	if c.s != nil {
+		return c.s, nil
+	}
+
+	return c.load()
+}
+
+// Discover returns Terraform API.
+// Every resource type found in the state is mapped into an API resource
+// whose group is the name of the resource provider.
+// The loaded state is reused by Map.
+func (c *client) Discover() (api.API, error) {
+	s, err := c.load()
+	if err != nil {
+		return nil, err
+	}
+	c.s = s
+
+	a := gen.NewAPI("terraform")
+
+	add := func(res *gen.Resource) {
+		a.AddResource(res)
+		for _, path := range res.Paths() {
+			a.IndexPath(res, path)
+		}
+	}
+
+	add(gen.NewResource("modules", ModuleKind, Group, Version, true))
+
+	seen := make(map[string]bool)
+
+	for _, r := range s.Resources {
+		if seen[r.kind()] {
+			continue
+		}
+		seen[r.kind()] = true
+
+		add(gen.NewResource(r.kind(), r.kind(), r.provider(), Version, true))
+	}
+
+	return a, nil
+}
+
+// Map maps the state file into API topology.
+// Resource instances are placed into the namespace of their module.
+// Dependencies on resources missing from the state are ignored.
+func (c *client) Map(a api.API) (api.Top, error) {
+	s, err := c.cached()
+	if err != nil {
+		return nil, err
+	}
+
+	resources := make(map[string]api.Resource)
+	for _, res := range a.Resources() {
+		resources[res.Kind()] = res
+	}
+
+	modRes, ok := resources[ModuleKind]
+	if !ok {
+		return nil, fmt.Errorf("resource %s: %w", ModuleKind, errors.ErrMissingResource)
+	}
+
+	src := gen.FromSource(a.Source())
+
+	top := gen.NewTop()
+
+	mods := make(map[string]bool)
+	// instances maps resource addresses stripped of instance keys to their instance objects
+	instances := make(map[string][]*gen.Object)
+	// deps maps instance objects to their dependencies
+	deps := make(map[*gen.Object][]string)
+
+	for _, r := range s.Resources {
+		res, ok := resources[r.kind()]
+		if !ok {
+			return nil, fmt.Errorf("resource %s: %w", r.kind(), errors.ErrMissingResource)
+		}
+
+		parent := ""
+		for _, mod := range modules(r.Module) {
+			if !mods[mod] {
+				mods[mod] = true
+
+				obj := gen.NewObject(uuid.NewFromString(mod), mod, parent, modRes, src)
+				if len(parent) > 0 {
+					obj.Link(uuid.NewFromString(parent), gen.NewRelation(MemberOfRel))
+				}
+				top.Add(obj)
+			}
+			parent = mod
+		}
+
+		addr := stripKeys(r.address())
+
+		for _, inst := range r.Instances {
+			name := r.Name + inst.key()
+			uid := uuid.NewFromString(r.address() + inst.key())
+
+			obj := gen.NewObject(uid, name, r.Module, res,
+				gen.Labels(tags(inst.Attributes)),
+				gen.Fields(fields(inst.Attributes)),
+				src,
+			)
+
+			if len(r.Module) > 0 {
+				obj.Link(uuid.NewFromString(r.Module), gen.NewRelation(MemberOfRel))
+			}
+
+			instances[addr] = append(instances[addr], obj)
+			deps[obj] = inst.Dependencies
+			top.Add(obj)
+		}
+	}
+
+	for obj, addrs := range deps {
+		for _, dep := range addrs {
+			for _, to := range instances[stripKeys(dep)] {
+				obj.Link(to.UID(), gen.NewRelation(DependsOnRel))
+			}
+		}
+	}
+
+	return top, nil
+}
+
+// tags returns string values of the instance tags attribute
+func tags(attrs map[string]interface{}) map[string]string {
+	t, ok := attrs["tags"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	labels := make(map[string]string)
+	for k, v := range t {
+		if s, ok := v.(string); ok {
+			labels[k] = s
+		}
+	}
+
+	return labels
+}
+
+// fields returns the instance id attribute as object fields
+func fields(attrs map[string]interface{}) map[string]string {
+	id, ok := attrs["id"].(string)
+	if !ok {
+		return nil
+	}
+
+	return map[string]string{"id": id}
+}
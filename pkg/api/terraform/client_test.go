@@ -0,0 +1,201 @@
+package terraform
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/milosgajdos/kraph/pkg/api"
+	"github.com/milosgajdos/kraph/pkg/query"
+)
+
+const (
+	statePath = "seeds/terraform.tfstate"
+)
+
+func TestDiscover(t *testing.T) {
+	c := NewClient(statePath)
+
+	a, err := c.Discover()
+	if err != nil {
+		t.Fatalf("failed to discover API: %v", err)
+	}
+
+	testCases := []struct {
+		kind  string
+		group string
+	}{
+		{ModuleKind, Group},
+		{"aws_vpc", "aws"},
+		{"aws_instance", "aws"},
+		{"aws_subnet", "aws"},
+		{"data.aws_ami", "aws"},
+		{"random_id", "random"},
+	}
+
+	if count := len(a.Resources()); count != len(testCases) {
+		t.Errorf("expected resources: %d, got: %d", len(testCases), count)
+	}
+
+	for _, tc := range testCases {
+		res, err := a.Get(query.Build().Kind(tc.kind))
+		if err != nil {
+			t.Errorf("failed to get resource %s: %v", tc.kind, err)
+			continue
+		}
+
+		if len(res) != 1 || res[0].Group() != tc.group {
+			t.Errorf("unexpected resources for kind %s: %v", tc.kind, res)
+		}
+	}
+}
+
+func TestMap(t *testing.T) {
+	c := NewClient(statePath)
+
+	a, err := c.Discover()
+	if err != nil {
+		t.Fatalf("failed to discover API: %v", err)
+	}
+
+	top, err := c.Map(a)
+	if err != nil {
+		t.Fatalf("failed to map API: %v", err)
+	}
+
+	objects := make(map[string]api.Object)
+	for _, obj := range top.Objects() {
+		if src := obj.Source(); src == nil || src.String() != "terraform" {
+			t.Errorf("%s: expected source: %s, got: %v", obj.UID(), "terraform", src)
+		}
+		objects[obj.UID().String()] = obj
+	}
+
+	// 2 modules, 6 resource instances
+	if count := len(objects); count != 8 {
+		t.Errorf("expected objects: %d, got: %d", 8, count)
+	}
+
+	app := `module.app["web.prod"]`
+	net := app + `.module.net`
+
+	testCases := []struct {
+		uid   string
+		ns    string
+		links []string
+	}{
+		{app, "", nil},
+		{net, app, []string{MemberOfRel + " " + app}},
+		{"aws_vpc.main", "", nil},
+		{"data.aws_ami.ubuntu", "", nil},
+		{"random_id.suffix", "", nil},
+		{net + ".aws_subnet.private", net, []string{
+			DependsOnRel + " aws_vpc.main",
+			MemberOfRel + " " + net,
+		}},
+		{app + ".aws_instance.server[0]", app, []string{
+			DependsOnRel + " aws_vpc.main",
+			DependsOnRel + " data.aws_ami.ubuntu",
+			DependsOnRel + " " + net + ".aws_subnet.private",
+			MemberOfRel + " " + app,
+		}},
+	}
+
+	for _, tc := range testCases {
+		obj, ok := objects[tc.uid]
+		if !ok {
+			t.Errorf("object %s not found", tc.uid)
+			continue
+		}
+
+		if obj.Namespace() != tc.ns {
+			t.Errorf("%s: expected namespace: %s, got: %s", tc.uid, tc.ns, obj.Namespace())
+		}
+
+		var links []string
+		for _, l := range obj.Links() {
+			links = append(links, l.Relation().String()+" "+l.To().String())
+		}
+		sort.Strings(links)
+
+		if len(links) != len(tc.links) {
+			t.Errorf("%s: expected links: %v, got: %v", tc.uid, tc.links, links)
+			continue
+		}
+
+		for i := range links {
+			if links[i] != tc.links[i] {
+				t.Errorf("%s: expected links: %v, got: %v", tc.uid, tc.links, links)
+				break
+			}
+		}
+	}
+
+	vpc := objects["aws_vpc.main"]
+
+	if l := vpc.Labels()["env"]; l != "prod" {
+		t.Errorf("expected label: %s, got: %s", "prod", l)
+	}
+
+	if id := vpc.Fields()["id"]; id != "vpc-0123" {
+		t.Errorf("expected id: %s, got: %s", "vpc-0123", id)
+	}
+}
+
+func TestUnsupportedVersion(t *testing.T) {
+	dir, err := ioutil.TempDir("", "terraform")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "terraform.tfstate")
+	if err := ioutil.WriteFile(path, []byte(`{"version": 3, "modules": []}`), 0644); err != nil {
+		t.Fatalf("failed to write state file: %v", err)
+	}
+
+	if _, err := NewClient(path).Discover(); err == nil {
+		t.Errorf("expected error for unsupported state version")
+	}
+}
+
+func TestMapDiscovered(t *testing.T) {
+	data, err := ioutil.ReadFile(statePath)
+	if err != nil {
+		t.Fatalf("failed to read state file: %v", err)
+	}
+
+	dir, err := ioutil.TempDir("", "terraform")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "terraform.tfstate")
+	if err := ioutil.WriteFile(path, data, 0644); err != nil {
+		t.Fatalf("failed to write state file: %v", err)
+	}
+
+	c := NewClient(path)
+
+	a, err := c.Discover()
+	if err != nil {
+		t.Fatalf("failed to discover API: %v", err)
+	}
+
+	// the state discovered by Discover is mapped even if the file changes
+	if err := ioutil.WriteFile(path, []byte(`{"version": 3, "modules": []}`), 0644); err != nil {
+		t.Fatalf("failed to write state file: %v", err)
+	}
+
+	top, err := c.Map(a)
+	if err != nil {
+		t.Fatalf("failed to map API: %v", err)
+	}
+
+	if count := len(top.Objects()); count != 8 {
+		t.Errorf("expected objects: %d, got: %d", 8, count)
+	}
+}
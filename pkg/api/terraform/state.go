@@ -0,0 +1,148 @@
+package terraform
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+const (
+	// stateVersion is the supported state format version
+	stateVersion = 4
+	// dataMode is the mode of data source resources
+	dataMode = "data"
+)
+
+// state is Terraform state
+type state struct {
+	Version   int        `json:"version"`
+	Resources []resource `json:"resources"`
+}
+
+// resource is Terraform state resource
+type resource struct {
+	Module    string     `json:"module,omitempty"`
+	Mode      string     `json:"mode"`
+	Type      string     `json:"type"`
+	Name      string     `json:"name"`
+	Provider  string     `json:"provider"`
+	Instances []instance `json:"instances"`
+}
+
+// kind returns resource kind.
+// Data source kinds are prefixed with data.
+func (r resource) kind() string {
+	if r.Mode == dataMode {
+		return dataMode + "." + r.Type
+	}
+
+	return r.Type
+}
+
+// address returns resource address
+func (r resource) address() string {
+	addr := r.kind() + "." + r.Name
+	if len(r.Module) > 0 {
+		addr = r.Module + "." + addr
+	}
+
+	return addr
+}
+
+// provider returns the name of the resource provider.
+// It handles both the provider["registry.terraform.io/hashicorp/aws"]
+// and the legacy provider.aws provider addresses.
+func (r resource) provider() string {
+	p := r.Provider
+
+	if i := strings.Index(p, `["`); i >= 0 {
+		p = p[i+2:]
+		if j := strings.Index(p, `"]`); j >= 0 {
+			p = p[:j]
+		}
+		return p[strings.LastIndex(p, "/")+1:]
+	}
+
+	parts := splitAddr(p)
+	for i := range parts {
+		if parts[i] == "provider" && i+1 < len(parts) {
+			return parts[i+1]
+		}
+	}
+
+	return p
+}
+
+// instance is Terraform state resource instance
+type instance struct {
+	IndexKey     json.RawMessage        `json:"index_key,omitempty"`
+	Attributes   map[string]interface{} `json:"attributes,omitempty"`
+	Dependencies []string               `json:"dependencies,omitempty"`
+}
+
+// key returns instance key address suffix
+func (i instance) key() string {
+	if len(i.IndexKey) == 0 {
+		return ""
+	}
+
+	return "[" + string(i.IndexKey) + "]"
+}
+
+// splitAddr splits address into its dot separated parts
+// ignoring the dots inside the instance keys.
+func splitAddr(addr string) []string {
+	var parts []string
+
+	depth, quoted, start := 0, false, 0
+
+	for i := 0; i < len(addr); i++ {
+		switch c := addr[i]; {
+		case quoted:
+			if c == '\\' {
+				i++
+			} else if c == '"' {
+				quoted = false
+			}
+		case c == '"':
+			quoted = true
+		case c == '[':
+			depth++
+		case c == ']':
+			depth--
+		case c == '.' && depth == 0:
+			parts = append(parts, addr[start:i])
+			start = i + 1
+		}
+	}
+
+	return append(parts, addr[start:])
+}
+
+// stripKeys removes all instance keys from address
+func stripKeys(addr string) string {
+	parts := splitAddr(addr)
+	for i, p := range parts {
+		if j := strings.Index(p, "["); j >= 0 {
+			parts[i] = p[:j]
+		}
+	}
+
+	return strings.Join(parts, ".")
+}
+
+// modules returns the addresses of module and all of its ancestor modules
+// ordered from the top level module to the module itself.
+func modules(module string) []string {
+	if len(module) == 0 {
+		return nil
+	}
+
+	var addrs []string
+
+	parts := splitAddr(module)
+	for i := 1; i < len(parts); i += 2 {
+		addrs = append(addrs, strings.Join(parts[:i+1], "."))
+	}
+
+	return addrs
+}
@@ -0,0 +1,64 @@
+package terraform
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitAddr(t *testing.T) {
+	testCases := []struct {
+		addr  string
+		parts []string
+	}{
+		{"aws_vpc.main", []string{"aws_vpc", "main"}},
+		{`module.app["web.prod"].aws_instance.server[0]`, []string{"module", `app["web.prod"]`, "aws_instance", "server[0]"}},
+		{`module.a["x\".y"].b`, []string{"module", `a["x\".y"]`, "b"}},
+	}
+
+	for _, tc := range testCases {
+		if parts := splitAddr(tc.addr); !reflect.DeepEqual(parts, tc.parts) {
+			t.Errorf("%s: expected parts: %v, got: %v", tc.addr, tc.parts, parts)
+		}
+	}
+}
+
+func TestStripKeys(t *testing.T) {
+	addr := `module.app["web.prod"].module.net[1].aws_subnet.private[0]`
+	exp := "module.app.module.net.aws_subnet.private"
+
+	if stripped := stripKeys(addr); stripped != exp {
+		t.Errorf("expected address: %s, got: %s", exp, stripped)
+	}
+}
+
+func TestModules(t *testing.T) {
+	mods := modules(`module.app["web.prod"].module.net`)
+	exp := []string{`module.app["web.prod"]`, `module.app["web.prod"].module.net`}
+
+	if !reflect.DeepEqual(mods, exp) {
+		t.Errorf("expected modules: %v, got: %v", exp, mods)
+	}
+
+	if mods := modules(""); len(mods) != 0 {
+		t.Errorf("expected no modules, got: %v", mods)
+	}
+}
+
+func TestProvider(t *testing.T) {
+	testCases := []struct {
+		provider string
+		name     string
+	}{
+		{`provider["registry.terraform.io/hashicorp/aws"]`, "aws"},
+		{`provider["registry.terraform.io/hashicorp/aws"].west`, "aws"},
+		{"provider.random", "random"},
+		{"module.app.provider.google", "google"},
+	}
+
+	for _, tc := range testCases {
+		r := resource{Provider: tc.provider}
+		if name := r.provider(); name != tc.name {
+			t.Errorf("%s: expected provider: %s, got: %s", tc.provider, tc.name, name)
+		}
+	}
+}
@@ -2,22 +2,31 @@ package types
 
 // Object is an API object
 type Object struct {
-	UID       string                 `json:"uid"`
-	Name      string                 `json:"name"`
-	Namespace string                 `json:"namespace"`
-	Resource  Resource               `json:"resource"`
-	Links     []Link                 `json:"links"`
-	Metadata  map[string]interface{} `json:"metadata,omitempty"`
+	UID         string                 `json:"uid"`
+	Name        string                 `json:"name"`
+	Namespace   string                 `json:"namespace"`
+	Resource    Resource               `json:"resource"`
+	Links       []Link                 `json:"links"`
+	Labels      map[string]string      `json:"labels,omitempty"`
+	Annotations map[string]string      `json:"annotations,omitempty"`
+	Fields      map[string]string      `json:"fields,omitempty"`
+	Metadata    map[string]interface{} `json:"metadata,omitempty"`
 }
 
 // Resource is an API resource
 type Resource struct {
-	Name       string                 `json:"name"`
-	Kind       string                 `json:"kind"`
-	Group      string                 `json:"group"`
-	Version    string                 `json:"version"`
-	Namespaced bool                   `json:"namespaced"`
-	Metadata   map[string]interface{} `json:"metadata,omitempty"`
+	Name             string                 `json:"name"`
+	Kind             string                 `json:"kind"`
+	Group            string                 `json:"group"`
+	Version          string                 `json:"version"`
+	Namespaced       bool                   `json:"namespaced"`
+	SingularName     string                 `json:"singularName,omitempty"`
+	ShortNames       []string               `json:"shortNames,omitempty"`
+	Categories       []string               `json:"categories,omitempty"`
+	Verbs            []string               `json:"verbs,omitempty"`
+	PreferredVersion string                 `json:"preferredVersion,omitempty"`
+	Deprecated       bool                   `json:"deprecated,omitempty"`
+	Metadata         map[string]interface{} `json:"metadata,omitempty"`
 }
 
 // Link is an API link
@@ -9,6 +9,8 @@ var (
 	ErrEntityMissing = err.New("entity missing")
 	// ErrUnknownObject is returned when requesting an unknown object
 	ErrUnknownObject = err.New("unknown object")
+	// ErrUnknownResource is returned when requesting an unknown API resource
+	ErrUnknownResource = err.New("unknown resource")
 	// ErrInvalidEntity is returned when requesting an invalid store entity
 	ErrInvalidEntity = err.New("invalid entity")
 	// ErrUnknownEntity is returned when requesting an unknown store entity
@@ -23,4 +25,10 @@ var (
 	ErrDuplicateNode = err.New("duplicate node")
 	// ErrMissingResource is returned by store when api.Object misses api.Resource
 	ErrMissingResource = err.New("missing resource")
+	// ErrNodeHasEdges is returned when deleting a node which has edges without cascading the delete
+	ErrNodeHasEdges = err.New("node has edges")
+	// ErrTxDone is returned when using a transaction which has already been committed or rolled back
+	ErrTxDone = err.New("transaction done")
+	// ErrTxConflict is returned when committing a transaction which conflicts with concurrent store writes
+	ErrTxConflict = err.New("transaction conflict")
 )
@@ -27,6 +27,7 @@ func (m metadata) Keys() []string {
 	i := 0
 	for key := range m {
 		keys[i] = key
+		i++
 	}
 
 	return keys
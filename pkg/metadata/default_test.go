@@ -36,3 +36,23 @@ func TestMetadataSet(t *testing.T) {
 		t.Errorf("expected %d keys, got: %d", exp, count)
 	}
 }
+
+func TestMetadataKeys(t *testing.T) {
+	m := New()
+
+	exp := map[string]bool{"foo": true, "bar": true}
+	for k := range exp {
+		m.Set(k, k)
+	}
+
+	keys := m.Keys()
+	if count := len(keys); count != len(exp) {
+		t.Errorf("expected %d keys, got: %d", len(exp), count)
+	}
+
+	for _, k := range keys {
+		if !exp[k] {
+			t.Errorf("unexpected key: %q", k)
+		}
+	}
+}
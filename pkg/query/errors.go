@@ -3,10 +3,22 @@ package query
 import "errors"
 
 var (
+	// ErrInvalidUID is returned when uid could not be decoded from query
+	ErrInvalidUID = errors.New("invalid uid")
+	// ErrInvalidNamespace is returned when namespace could not be decoded from query
+	ErrInvalidNamespace = errors.New("invalid namespace")
+	// ErrInvalidKind is returned when kind could not be decoded from query
+	ErrInvalidKind = errors.New("invalid kind")
 	// ErrInvalidName is returned when name could not be decoded from query
 	ErrInvalidName = errors.New("invalid name")
+	// ErrInvalidSource is returned when source could not be decoded from query
+	ErrInvalidSource = errors.New("invalid source")
 	// ErrInvalidGroup is returned when group could not be decoded from query
 	ErrInvalidGroup = errors.New("invalid group")
 	// ErrInvalidVersion is returned when version could not be decoded from query
 	ErrInvalidVersion = errors.New("invalid version")
+	// ErrInvalidPattern is returned when match pattern is malformed
+	ErrInvalidPattern = errors.New("invalid pattern")
+	// ErrInvalidRange is returned when match range is malformed
+	ErrInvalidRange = errors.New("invalid range")
 )
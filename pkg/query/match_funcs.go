@@ -1,14 +1,21 @@
 package query
 
 import (
-	"math/big"
+	"fmt"
+	"math"
+	"path"
 	"reflect"
+	"regexp"
+	"strings"
 
 	"github.com/milosgajdos/kraph/pkg/attrs"
 	"github.com/milosgajdos/kraph/pkg/metadata"
 	"github.com/milosgajdos/kraph/pkg/uuid"
 )
 
+// NOTE: all MatchFuncs return false when they are
+// given a value of a type they can not match against
+
 // IsAnyFunc always returns true
 func IsAnyFunc(v interface{}) bool {
 	return true
@@ -18,24 +25,137 @@ func IsAnyFunc(v interface{}) bool {
 // the equality of an arbitrary string to s1
 func StringEqFunc(s1 string) MatchFunc {
 	return func(s2 interface{}) bool {
-		return s1 == s2.(string)
+		s, ok := s2.(string)
+		return ok && s1 == s
+	}
+}
+
+// StringPrefixFunc returns MatchFunc which checks
+// if an arbitrary string has the given prefix
+func StringPrefixFunc(prefix string) MatchFunc {
+	return func(s2 interface{}) bool {
+		s, ok := s2.(string)
+		return ok && strings.HasPrefix(s, prefix)
+	}
+}
+
+// StringRegexFunc returns MatchFunc which checks
+// if an arbitrary string matches regular expression expr.
+// It returns error if expr fails to compile.
+func StringRegexFunc(expr string) (MatchFunc, error) {
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", err, ErrInvalidPattern)
+	}
+
+	return func(s2 interface{}) bool {
+		s, ok := s2.(string)
+		return ok && re.MatchString(s)
+	}, nil
+}
+
+// StringGlobFunc returns MatchFunc which checks if an arbitrary
+// string matches shell glob pattern as defined by path.Match.
+// It returns error if the pattern is malformed.
+func StringGlobFunc(pattern string) (MatchFunc, error) {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, fmt.Errorf("%s: %w", pattern, ErrInvalidPattern)
+	}
+
+	return func(s2 interface{}) bool {
+		s, ok := s2.(string)
+		if !ok {
+			return false
+		}
+		match, err := path.Match(pattern, s)
+		return err == nil && match
+	}, nil
+}
+
+// StringInFunc returns MatchFunc which checks
+// if an arbitrary string is in the set ss
+func StringInFunc(ss ...string) MatchFunc {
+	set := make(map[string]bool, len(ss))
+	for _, s := range ss {
+		set[s] = true
+	}
+
+	return func(s2 interface{}) bool {
+		s, ok := s2.(string)
+		return ok && set[s]
+	}
+}
+
+// StringNotInFunc returns MatchFunc which checks
+// if an arbitrary string is not in the set ss
+func StringNotInFunc(ss ...string) MatchFunc {
+	in := StringInFunc(ss...)
+
+	return func(s2 interface{}) bool {
+		if _, ok := s2.(string); !ok {
+			return false
+		}
+		return !in(s2)
+	}
+}
+
+// floatFunc returns MatchFunc which checks if an arbitrary float
+// satisfies cmp. It never matches non-float values.
+// NaN values never match as all the float comparisons with NaN are false.
+func floatFunc(cmp func(float64) bool) MatchFunc {
+	return func(f2 interface{}) bool {
+		f, ok := f2.(float64)
+		return ok && cmp(f)
 	}
 }
 
 // FloatEqFunc returns MatchFunc which checks
 // the equality of an arbitrary float to f1
 func FloatEqFunc(f1 float64) MatchFunc {
-	return func(f2 interface{}) bool {
-		return big.NewFloat(f1).Cmp(big.NewFloat(f2.(float64))) != 0
+	return floatFunc(func(f float64) bool { return f == f1 })
+}
+
+// FloatLtFunc returns MatchFunc which checks
+// if an arbitrary float is less than f1
+func FloatLtFunc(f1 float64) MatchFunc {
+	return floatFunc(func(f float64) bool { return f < f1 })
+}
+
+// FloatLteFunc returns MatchFunc which checks
+// if an arbitrary float is less than or equal to f1
+func FloatLteFunc(f1 float64) MatchFunc {
+	return floatFunc(func(f float64) bool { return f <= f1 })
+}
+
+// FloatGtFunc returns MatchFunc which checks
+// if an arbitrary float is greater than f1
+func FloatGtFunc(f1 float64) MatchFunc {
+	return floatFunc(func(f float64) bool { return f > f1 })
+}
+
+// FloatGteFunc returns MatchFunc which checks
+// if an arbitrary float is greater than or equal to f1
+func FloatGteFunc(f1 float64) MatchFunc {
+	return floatFunc(func(f float64) bool { return f >= f1 })
+}
+
+// FloatRangeFunc returns MatchFunc which checks if an arbitrary
+// float is in the closed interval [min, max].
+// It returns error if min is greater than max or if either of them is NaN.
+func FloatRangeFunc(min, max float64) (MatchFunc, error) {
+	if math.IsNaN(min) || math.IsNaN(max) || min > max {
+		return nil, fmt.Errorf("[%f, %f]: %w", min, max, ErrInvalidRange)
 	}
+
+	return floatFunc(func(f float64) bool { return f >= min && f <= max }), nil
 }
 
 // UIDEqFunc returns MatchFunc which checks
 // the equality of an arbitrary uid to u1
 func UIDEqFunc(u1 uuid.UID) MatchFunc {
 	return func(u2 interface{}) bool {
-		uid := u2.(uuid.UID)
-		return u1.String() == uid.String()
+		uid, ok := u2.(uuid.UID)
+		return ok && u1.String() == uid.String()
 	}
 }
 
@@ -48,12 +168,39 @@ func EntityEqFunc(e1 Entity) MatchFunc {
 }
 
 // HasAttrsFunc returns MatchFunc which checks
-// if a contains k/v of an arbitrary attrs.Attrs
+// if an arbitrary attrs.Attrs contains all k/v of a
 func HasAttrsFunc(a attrs.Attrs) MatchFunc {
 	return func(a2 interface{}) bool {
-		a2attrs := a2.(attrs.Attrs)
+		a2attrs, ok := a2.(attrs.Attrs)
+		if !ok {
+			return false
+		}
+
+		for _, k := range a.Keys() {
+			if v := a2attrs.Get(k); v != a.Get(k) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// HasAttrKeysFunc returns MatchFunc which checks
+// if an arbitrary attrs.Attrs contains all the given keys
+func HasAttrKeysFunc(keys ...string) MatchFunc {
+	return func(a2 interface{}) bool {
+		a2attrs, ok := a2.(attrs.Attrs)
+		if !ok {
+			return false
+		}
+
+		set := make(map[string]bool)
 		for _, k := range a2attrs.Keys() {
-			if v := a.Get(k); v != a2attrs.Get(k) {
+			set[k] = true
+		}
+
+		for _, k := range keys {
+			if !set[k] {
 				return false
 			}
 		}
@@ -61,13 +208,36 @@ func HasAttrsFunc(a attrs.Attrs) MatchFunc {
 	}
 }
 
+// AttrRegexFunc returns MatchFunc which checks if an arbitrary
+// attrs.Attrs contains key whose value matches regular expression expr.
+// It returns error if expr fails to compile.
+func AttrRegexFunc(key, expr string) (MatchFunc, error) {
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", err, ErrInvalidPattern)
+	}
+
+	hasKey := HasAttrKeysFunc(key)
+
+	return func(a2 interface{}) bool {
+		if !hasKey(a2) {
+			return false
+		}
+		return re.MatchString(a2.(attrs.Attrs).Get(key))
+	}, nil
+}
+
 // HasMetadataFunc returns MatchFunc which checks
-// if m contains k/v of an arbitrary metadata.Metadata
+// if an arbitrary metadata.Metadata contains all k/v of m
 func HasMetadataFunc(m metadata.Metadata) MatchFunc {
 	return func(m2 interface{}) bool {
-		m2meta := m2.(attrs.Attrs)
-		for _, k := range m2meta.Keys() {
-			if v := m.Get(k); !reflect.DeepEqual(v, m2meta.Get(k)) {
+		m2meta, ok := m2.(metadata.Metadata)
+		if !ok {
+			return false
+		}
+
+		for _, k := range m.Keys() {
+			if v := m2meta.Get(k); !reflect.DeepEqual(v, m.Get(k)) {
 				return false
 			}
 		}
@@ -0,0 +1,234 @@
+package query
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/milosgajdos/kraph/pkg/attrs"
+	"github.com/milosgajdos/kraph/pkg/metadata"
+	"github.com/milosgajdos/kraph/pkg/uuid"
+)
+
+type matchTest struct {
+	val interface{}
+	exp bool
+}
+
+func testMatchFunc(t *testing.T, name string, fn MatchFunc, tests []matchTest) {
+	for _, test := range tests {
+		if res := fn(test.val); res != test.exp {
+			t.Errorf("%s(%#v): expected: %v, got: %v", name, test.val, test.exp, res)
+		}
+	}
+}
+
+func TestIsAnyFunc(t *testing.T) {
+	testMatchFunc(t, "IsAny", IsAnyFunc, []matchTest{
+		{"foo", true},
+		{nil, true},
+		{1.0, true},
+	})
+}
+
+func TestStringFuncs(t *testing.T) {
+	testMatchFunc(t, "StringEq", StringEqFunc("foo"), []matchTest{
+		{"foo", true},
+		{"bar", false},
+		{1, false},
+	})
+
+	testMatchFunc(t, "StringPrefix", StringPrefixFunc("foo"), []matchTest{
+		{"foobar", true},
+		{"barfoo", false},
+		{nil, false},
+		{math.NaN(), false},
+	})
+
+	testMatchFunc(t, "StringIn", StringInFunc("foo", "bar"), []matchTest{
+		{"foo", true},
+		{"bar", true},
+		{"baz", false},
+		{1, false},
+	})
+
+	testMatchFunc(t, "StringNotIn", StringNotInFunc("foo", "bar"), []matchTest{
+		{"foo", false},
+		{"baz", true},
+		{1, false},
+	})
+}
+
+func TestStringRegexFunc(t *testing.T) {
+	if _, err := StringRegexFunc("(["); !errors.Is(err, ErrInvalidPattern) {
+		t.Errorf("expected error: %v, got: %v", ErrInvalidPattern, err)
+	}
+
+	fn, err := StringRegexFunc("^foo-[0-9]+$")
+	if err != nil {
+		t.Fatalf("failed to create match func: %v", err)
+	}
+
+	testMatchFunc(t, "StringRegex", fn, []matchTest{
+		{"foo-123", true},
+		{"foo-bar", false},
+		{123, false},
+	})
+}
+
+func TestStringGlobFunc(t *testing.T) {
+	if _, err := StringGlobFunc("[foo"); !errors.Is(err, ErrInvalidPattern) {
+		t.Errorf("expected error: %v, got: %v", ErrInvalidPattern, err)
+	}
+
+	fn, err := StringGlobFunc("kube-*")
+	if err != nil {
+		t.Fatalf("failed to create match func: %v", err)
+	}
+
+	testMatchFunc(t, "StringGlob", fn, []matchTest{
+		{"kube-system", true},
+		{"default", false},
+		{1, false},
+	})
+}
+
+func TestFloatFuncs(t *testing.T) {
+	testMatchFunc(t, "FloatEq", FloatEqFunc(2.0), []matchTest{
+		{2.0, true},
+		{3.0, false},
+		{"2.0", false},
+		{math.NaN(), false},
+	})
+
+	testMatchFunc(t, "FloatEqNaN", FloatEqFunc(math.NaN()), []matchTest{
+		{math.NaN(), false},
+		{2.0, false},
+	})
+
+	testMatchFunc(t, "FloatLt", FloatLtFunc(2.0), []matchTest{
+		{1.0, true},
+		{2.0, false},
+		{3.0, false},
+		{1, false},
+		{math.NaN(), false},
+	})
+
+	testMatchFunc(t, "FloatLtNaN", FloatLtFunc(math.NaN()), []matchTest{
+		{1.0, false},
+		{math.NaN(), false},
+	})
+
+	testMatchFunc(t, "FloatLte", FloatLteFunc(2.0), []matchTest{
+		{1.0, true},
+		{2.0, true},
+		{3.0, false},
+		{nil, false},
+	})
+
+	testMatchFunc(t, "FloatGt", FloatGtFunc(2.0), []matchTest{
+		{1.0, false},
+		{2.0, false},
+		{3.0, true},
+		{"3", false},
+		{math.NaN(), false},
+	})
+
+	testMatchFunc(t, "FloatGte", FloatGteFunc(2.0), []matchTest{
+		{1.0, false},
+		{2.0, true},
+		{3.0, true},
+		{3, false},
+		{math.NaN(), false},
+	})
+}
+
+func TestFloatRangeFunc(t *testing.T) {
+	for _, r := range [][2]float64{{2.0, 1.0}, {math.NaN(), 1.0}, {1.0, math.NaN()}} {
+		if _, err := FloatRangeFunc(r[0], r[1]); !errors.Is(err, ErrInvalidRange) {
+			t.Errorf("[%f, %f]: expected error: %v, got: %v", r[0], r[1], ErrInvalidRange, err)
+		}
+	}
+
+	fn, err := FloatRangeFunc(1.0, 2.0)
+	if err != nil {
+		t.Fatalf("failed to create match func: %v", err)
+	}
+
+	testMatchFunc(t, "FloatRange", fn, []matchTest{
+		{0.5, false},
+		{1.0, true},
+		{1.5, true},
+		{2.0, true},
+		{2.5, false},
+		{"1.5", false},
+		{math.NaN(), false},
+	})
+}
+
+func TestUIDEqFunc(t *testing.T) {
+	testMatchFunc(t, "UIDEq", UIDEqFunc(uuid.NewFromString("foo")), []matchTest{
+		{uuid.NewFromString("foo"), true},
+		{uuid.NewFromString("bar"), false},
+		{"foo", false},
+	})
+}
+
+func TestEntityEqFunc(t *testing.T) {
+	testMatchFunc(t, "EntityEq", EntityEqFunc(Node), []matchTest{
+		{Node, true},
+		{Edge, false},
+		{"node", false},
+	})
+}
+
+func TestAttrsFuncs(t *testing.T) {
+	a := attrs.New()
+	a.Set("foo", "bar")
+	a.Set("app", "web-1")
+
+	q := attrs.New()
+	q.Set("foo", "bar")
+
+	testMatchFunc(t, "HasAttrs", HasAttrsFunc(q), []matchTest{
+		{a, true},
+		{attrs.New(), false},
+		{"foo", false},
+	})
+
+	testMatchFunc(t, "HasAttrKeys", HasAttrKeysFunc("foo", "app"), []matchTest{
+		{a, true},
+		{q, false},
+		{nil, false},
+	})
+
+	if _, err := AttrRegexFunc("app", "(["); !errors.Is(err, ErrInvalidPattern) {
+		t.Errorf("expected error: %v, got: %v", ErrInvalidPattern, err)
+	}
+
+	fn, err := AttrRegexFunc("app", "^web-")
+	if err != nil {
+		t.Fatalf("failed to create match func: %v", err)
+	}
+
+	testMatchFunc(t, "AttrRegex", fn, []matchTest{
+		{a, true},
+		{q, false},
+		{1, false},
+	})
+}
+
+func TestHasMetadataFunc(t *testing.T) {
+	m := metadata.New()
+	m.Set("foo", []string{"bar"})
+	m.Set("baz", 1)
+
+	q := metadata.New()
+	q.Set("foo", []string{"bar"})
+
+	testMatchFunc(t, "HasMetadata", HasMetadataFunc(q), []matchTest{
+		{m, true},
+		{metadata.New(), false},
+		{attrs.New(), false},
+	})
+}
@@ -1,6 +1,8 @@
 package query
 
 import (
+	"reflect"
+
 	"github.com/milosgajdos/kraph/pkg/attrs"
 	"github.com/milosgajdos/kraph/pkg/metadata"
 	"github.com/milosgajdos/kraph/pkg/uuid"
@@ -14,23 +16,79 @@ const (
 
 type MatchFunc func(interface{}) bool
 
+// Matcher matches the values of a query property.
+// Stores and APIs use it to look up equality matcher values in their indices.
+type Matcher interface {
+	// Value returns matcher value
+	Value() interface{}
+	// IsEq returns true if the matcher matches values equal to its value
+	IsEq() bool
+	// Match returns true if the value matches
+	Match(interface{}) bool
+}
+
 type matcher struct {
 	val   interface{}
 	funcs []MatchFunc
+	eq    bool
 }
 
+// newMatcher creates a new matcher which matches values using funcs.
+// If no funcs are given the matcher matches values equal to val,
+// or values which contain val if val is attrs.Attrs or metadata.Metadata.
 func newMatcher(val interface{}, funcs ...MatchFunc) *matcher {
+	if len(funcs) == 0 {
+		return &matcher{
+			val:   val,
+			funcs: []MatchFunc{eqFunc(val)},
+			eq:    true,
+		}
+	}
+
 	return &matcher{
 		val:   val,
 		funcs: funcs,
 	}
 }
 
-func (m matcher) Value() interface{} {
+// eqFunc returns MatchFunc which checks the equality of an arbitrary value to v1
+func eqFunc(v1 interface{}) MatchFunc {
+	switch v := v1.(type) {
+	case uuid.UID:
+		return UIDEqFunc(v)
+	case attrs.Attrs:
+		return HasAttrsFunc(v)
+	case metadata.Metadata:
+		return HasMetadataFunc(v)
+	}
+
+	return func(v2 interface{}) bool {
+		return reflect.DeepEqual(v1, v2)
+	}
+}
+
+// Value returns matcher value.
+// It returns nil if the matcher is nil.
+func (m *matcher) Value() interface{} {
+	if m == nil {
+		return nil
+	}
 	return m.val
 }
 
-func (m matcher) Match(val interface{}) bool {
+// IsEq returns true if the matcher has been created without match funcs.
+// Stores can use the value of such matcher to look it up in their indices.
+func (m *matcher) IsEq() bool {
+	return m != nil && m.eq
+}
+
+// Match returns true if val matches all matcher funcs.
+// Nil matcher matches any value.
+func (m *matcher) Match(val interface{}) bool {
+	if m == nil {
+		return true
+	}
+
 	match := true
 	for _, fn := range m.funcs {
 		match = match && fn(val)
@@ -39,11 +97,12 @@ func (m matcher) Match(val interface{}) bool {
 	return match
 }
 
-type match struct {
+// Match matches query properties
+type Match struct {
 	q *Query
 }
 
-func (m match) matchVal(prop string, val interface{}) bool {
+func (m Match) matchVal(prop string, val interface{}) bool {
 	matcher, ok := m.q.matchers[prop]
 	if !ok {
 		return true
@@ -56,82 +115,107 @@ func (m match) matchVal(prop string, val interface{}) bool {
 	return matcher.Match(val)
 }
 
-func (m *match) UID() *matcher {
+// Limit returns the maximum number of results.
+// It returns 0 if the results are not limited.
+func (m *Match) Limit() int {
+	if m.q.limit < 0 {
+		return 0
+	}
+	return m.q.limit
+}
+
+// Offset returns the number of results to skip.
+func (m *Match) Offset() int {
+	if m.q.offset < 0 {
+		return 0
+	}
+	return m.q.offset
+}
+
+func (m *Match) UID() *matcher {
 	return m.q.matchers["uid"]
 }
 
-func (m *match) UIDVal(u uuid.UID) bool {
+func (m *Match) UIDVal(u uuid.UID) bool {
 	return m.matchVal("uid", u)
 }
 
-func (m *match) Namespace() *matcher {
+func (m *Match) Namespace() *matcher {
 	return m.q.matchers["ns"]
 }
 
-func (m *match) NamespaceVal(ns string) bool {
+func (m *Match) NamespaceVal(ns string) bool {
 	return m.matchVal("ns", ns)
 }
 
-func (m *match) Kind() *matcher {
+func (m *Match) Kind() *matcher {
 	return m.q.matchers["kind"]
 }
 
-func (m *match) KindVal(k string) bool {
+func (m *Match) KindVal(k string) bool {
 	return m.matchVal("kind", k)
 }
 
-func (m *match) Name() *matcher {
+func (m *Match) Name() *matcher {
 	return m.q.matchers["name"]
 }
 
-func (m *match) NameVal(n string) bool {
+func (m *Match) NameVal(n string) bool {
 	return m.matchVal("name", n)
 }
 
-func (m *match) Version() *matcher {
+func (m *Match) Source() *matcher {
+	return m.q.matchers["source"]
+}
+
+func (m *Match) SourceVal(s string) bool {
+	return m.matchVal("source", s)
+}
+
+func (m *Match) Version() *matcher {
 	return m.q.matchers["version"]
 }
 
-func (m *match) VersionVal(v string) bool {
+func (m *Match) VersionVal(v string) bool {
 	return m.matchVal("version", v)
 }
 
-func (m *match) Group() *matcher {
+func (m *Match) Group() *matcher {
 	return m.q.matchers["group"]
 }
 
-func (m *match) GroupVal(g string) bool {
+func (m *Match) GroupVal(g string) bool {
 	return m.matchVal("group", g)
 }
 
-func (m *match) Entity() *matcher {
+func (m *Match) Entity() *matcher {
 	return m.q.matchers["entity"]
 }
 
-func (m *match) EntityVal(e Entity) bool {
+func (m *Match) EntityVal(e Entity) bool {
 	return m.matchVal("entity", e)
 }
 
-func (m *match) Weight() *matcher {
+func (m *Match) Weight() *matcher {
 	return m.q.matchers["weight"]
 }
 
-func (m *match) WeightVal(w float64) bool {
+func (m *Match) WeightVal(w float64) bool {
 	return m.matchVal("weight", w)
 }
 
-func (m *match) Attrs() *matcher {
+func (m *Match) Attrs() *matcher {
 	return m.q.matchers["attrs"]
 }
 
-func (m *match) AttrsVal(a attrs.Attrs) bool {
+func (m *Match) AttrsVal(a attrs.Attrs) bool {
 	return m.matchVal("attrs", a)
 }
 
-func (m *match) Metadata() *matcher {
+func (m *Match) Metadata() *matcher {
 	return m.q.matchers["metadata"]
 }
 
-func (m *match) MetadataVal(meta metadata.Metadata) bool {
+func (m *Match) MetadataVal(meta metadata.Metadata) bool {
 	return m.matchVal("metadata", meta)
 }
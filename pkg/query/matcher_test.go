@@ -0,0 +1,80 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/milosgajdos/kraph/pkg/attrs"
+	"github.com/milosgajdos/kraph/pkg/uuid"
+)
+
+func TestMatcherEq(t *testing.T) {
+	a := attrs.New()
+	a.Set("foo", "bar")
+
+	a2 := attrs.New()
+	a2.Set("foo", "bar")
+	a2.Set("baz", "qux")
+
+	q := Build().
+		UID(uuid.NewFromString("fooUID")).
+		Namespace("fooNs").
+		Kind("fooKind", StringPrefixFunc("foo")).
+		Attrs(a)
+
+	match := q.Matcher()
+
+	if !match.UID().IsEq() || !match.Namespace().IsEq() || !match.Attrs().IsEq() {
+		t.Errorf("expected equality matchers")
+	}
+
+	if match.Kind().IsEq() || match.Name().IsEq() {
+		t.Errorf("unexpected equality matchers")
+	}
+
+	if !match.UIDVal(uuid.NewFromString("fooUID")) || match.UIDVal(uuid.NewFromString("barUID")) {
+		t.Errorf("failed to match uid")
+	}
+
+	if !match.NamespaceVal("fooNs") || match.NamespaceVal("barNs") {
+		t.Errorf("failed to match namespace")
+	}
+
+	if !match.KindVal("fooKindX") {
+		t.Errorf("failed to match kind")
+	}
+
+	// attrs equality matcher matches attrs which contain its value
+	if !match.AttrsVal(a2) || match.AttrsVal(attrs.New()) {
+		t.Errorf("failed to match attrs")
+	}
+
+	// nil matcher matches anything
+	var m *matcher
+	if m.IsEq() || m.Value() != nil || !m.Match("foo") {
+		t.Errorf("unexpected nil matcher behaviour")
+	}
+}
+
+func TestMatchPagination(t *testing.T) {
+	tests := []struct {
+		q      *Query
+		limit  int
+		offset int
+	}{
+		{Build(), 0, 0},
+		{Build().Limit(10).Offset(5), 10, 5},
+		{Build().Limit(-1).Offset(-1), 0, 0},
+	}
+
+	for _, test := range tests {
+		m := test.q.Matcher()
+
+		if l := m.Limit(); l != test.limit {
+			t.Errorf("expected limit: %d, got: %d", test.limit, l)
+		}
+
+		if o := m.Offset(); o != test.offset {
+			t.Errorf("expected offset: %d, got: %d", test.offset, o)
+		}
+	}
+}
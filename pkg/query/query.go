@@ -9,6 +9,8 @@ const (
 
 type Query struct {
 	matchers map[string]*matcher
+	limit    int
+	offset   int
 }
 
 func Build() *Query {
@@ -40,6 +42,11 @@ func (q *Query) Name(n interface{}, funcs ...MatchFunc) *Query {
 	return q.updateQuery("name", n, funcs...)
 }
 
+// Source matches the API source of the objects
+func (q *Query) Source(s interface{}, funcs ...MatchFunc) *Query {
+	return q.updateQuery("source", s, funcs...)
+}
+
 func (q *Query) Version(v interface{}, funcs ...MatchFunc) *Query {
 	return q.updateQuery("version", v, funcs...)
 }
@@ -64,8 +71,22 @@ func (q *Query) Metadata(m interface{}, funcs ...MatchFunc) *Query {
 	return q.updateQuery("metadata", m, funcs...)
 }
 
-func (q *Query) Matcher() *match {
-	return &match{
+// Limit limits the number of query results to n.
+// Zero or negative n means the results are not limited.
+func (q *Query) Limit(n int) *Query {
+	q.limit = n
+	return q
+}
+
+// Offset skips the first n query results.
+// Zero or negative n means no results are skipped.
+func (q *Query) Offset(n int) *Query {
+	q.offset = n
+	return q
+}
+
+func (q *Query) Matcher() *Match {
+	return &Match{
 		q: q,
 	}
 }
@@ -80,6 +101,7 @@ func (q *Query) MatchAny() *Query {
 		"ns",
 		"kind",
 		"name",
+		"source",
 		"version",
 		"group",
 		"entity",
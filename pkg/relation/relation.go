@@ -0,0 +1,151 @@
+package relation
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"sync"
+
+	"github.com/ghodss/yaml"
+	"github.com/milosgajdos/kraph/pkg/attrs"
+)
+
+var (
+	// ErrMissingName is returned when relation has no name
+	ErrMissingName = errors.New("missing relation name")
+	// ErrInvalidWeight is returned when relation weight is negative
+	ErrInvalidWeight = errors.New("invalid relation weight")
+)
+
+// Style is relation GraphViz DOT style
+type Style struct {
+	// Color is edge color
+	Color string `json:"color,omitempty"`
+	// ArrowHead is edge arrowhead shape
+	ArrowHead string `json:"arrowhead,omitempty"`
+	// Line is edge line style e.g. dashed, dotted
+	Line string `json:"line,omitempty"`
+}
+
+// Relation defines properties of API object relation
+type Relation struct {
+	// Name is relation name
+	Name string `json:"name"`
+	// Weight is the weight of the edges of the relation.
+	// If it is nil the edges are weighted with the default weight.
+	Weight *float64 `json:"weight,omitempty"`
+	// Directed is true if the relation is directed.
+	// Build marks the edges of the relation with store.DirectedAttr
+	// and the DOT exporters draw them with an arrow.
+	Directed bool `json:"directed"`
+	// Style is relation DOT style
+	Style Style `json:"style,omitempty"`
+}
+
+// DOTAttrs returns relation style as GraphViz DOT attributes
+func (r Relation) DOTAttrs() attrs.Attrs {
+	a := attrs.New()
+
+	if r.Directed {
+		a.Set("dir", "forward")
+	}
+
+	if r.Style.Color != "" {
+		a.Set("color", r.Style.Color)
+	}
+
+	if r.Style.ArrowHead != "" {
+		a.Set("arrowhead", r.Style.ArrowHead)
+	}
+
+	if r.Style.Line != "" {
+		a.Set("style", r.Style.Line)
+	}
+
+	return a
+}
+
+// Registry is a registry of relations.
+// It is safe to use the registry concurrently.
+type Registry struct {
+	// mu guards relations
+	mu        sync.RWMutex
+	relations map[string]Relation
+}
+
+// NewRegistry creates a new relation registry and returns it
+func NewRegistry(relations ...Relation) (*Registry, error) {
+	r := &Registry{
+		relations: make(map[string]Relation),
+	}
+
+	for _, rel := range relations {
+		if err := r.Register(rel); err != nil {
+			return nil, err
+		}
+	}
+
+	return r, nil
+}
+
+// LoadRegistry reads relations from YAML file and returns their registry
+func LoadRegistry(path string) (*Registry, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var relations []Relation
+	if err := yaml.Unmarshal(data, &relations); err != nil {
+		return nil, err
+	}
+
+	return NewRegistry(relations...)
+}
+
+// Register registers relation rel.
+// Registering an already registered relation overrides it.
+func (r *Registry) Register(rel Relation) error {
+	if rel.Name == "" {
+		return ErrMissingName
+	}
+
+	if rel.Weight != nil && *rel.Weight < 0 {
+		return fmt.Errorf("relation %s weight %f: %w", rel.Name, *rel.Weight, ErrInvalidWeight)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.relations[rel.Name] = rel
+
+	return nil
+}
+
+// Get returns relation with the given name.
+// It returns false if the relation has not been registered.
+func (r *Registry) Get(name string) (Relation, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	rel, ok := r.relations[name]
+	return rel, ok
+}
+
+// Relations returns all registered relations sorted by name
+func (r *Registry) Relations() []Relation {
+	r.mu.RLock()
+	relations := make([]Relation, 0, len(r.relations))
+
+	for _, rel := range r.relations {
+		relations = append(relations, rel)
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(relations, func(i, j int) bool {
+		return relations[i].Name < relations[j].Name
+	})
+
+	return relations
+}
@@ -0,0 +1,156 @@
+package relation
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+const (
+	relPath = "seeds/relations.yaml"
+)
+
+func weight(w float64) *float64 {
+	return &w
+}
+
+func TestRegistry(t *testing.T) {
+	r, err := NewRegistry()
+	if err != nil {
+		t.Fatalf("failed to create registry: %v", err)
+	}
+
+	if count := len(r.Relations()); count != 0 {
+		t.Errorf("expected relations: %d, got: %d", 0, count)
+	}
+
+	if err := r.Register(Relation{}); !errors.Is(err, ErrMissingName) {
+		t.Errorf("expected error: %v, got: %v", ErrMissingName, err)
+	}
+
+	if err := r.Register(Relation{Name: "foo", Weight: weight(-1.0)}); !errors.Is(err, ErrInvalidWeight) {
+		t.Errorf("expected error: %v, got: %v", ErrInvalidWeight, err)
+	}
+
+	rel := Relation{Name: "foo", Weight: weight(3.0)}
+	if err := r.Register(rel); err != nil {
+		t.Fatalf("failed to register relation: %v", err)
+	}
+
+	got, ok := r.Get(rel.Name)
+	if !ok {
+		t.Fatalf("relation %s not found", rel.Name)
+	}
+
+	if !reflect.DeepEqual(got, rel) {
+		t.Errorf("expected relation: %#v, got: %#v", rel, got)
+	}
+
+	if _, ok := r.Get("bar"); ok {
+		t.Errorf("expected relation bar to be missing")
+	}
+
+	// zero weight must be distinguishable from unset weight
+	if err := r.Register(Relation{Name: "zero", Weight: weight(0)}); err != nil {
+		t.Fatalf("failed to register relation: %v", err)
+	}
+
+	if zero, _ := r.Get("zero"); zero.Weight == nil || *zero.Weight != 0 {
+		t.Errorf("expected zero weight, got: %v", zero.Weight)
+	}
+}
+
+func TestRegistryConcurrent(t *testing.T) {
+	r, err := NewRegistry()
+	if err != nil {
+		t.Fatalf("failed to create registry: %v", err)
+	}
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+
+		go func(i int) {
+			defer wg.Done()
+			if err := r.Register(Relation{Name: fmt.Sprintf("rel%d", i)}); err != nil {
+				t.Errorf("failed to register relation: %v", err)
+			}
+		}(i)
+
+		go func(i int) {
+			defer wg.Done()
+			r.Get(fmt.Sprintf("rel%d", i))
+			r.Relations()
+		}(i)
+	}
+
+	wg.Wait()
+
+	if n := len(r.Relations()); n != 10 {
+		t.Errorf("expected relations: %d, got: %d", 10, n)
+	}
+}
+
+func TestLoadRegistry(t *testing.T) {
+	if _, err := LoadRegistry("nonexistent.yaml"); err == nil {
+		t.Errorf("expected error loading nonexistent file")
+	}
+
+	r, err := LoadRegistry(relPath)
+	if err != nil {
+		t.Fatalf("failed to load registry: %v", err)
+	}
+
+	relations := r.Relations()
+	if len(relations) != 2 {
+		t.Fatalf("expected relations: %d, got: %d", 2, len(relations))
+	}
+
+	// relations are sorted by name
+	if relations[0].Name != "foo-bar" || relations[1].Name != "isOwned" {
+		t.Errorf("unexpected relations: %v", relations)
+	}
+
+	owned := relations[1]
+	if !owned.Directed || owned.Style.Color != "red" || owned.Style.ArrowHead != "diamond" ||
+		owned.Weight == nil || *owned.Weight != 2 {
+		t.Errorf("unexpected relation: %#v", owned)
+	}
+}
+
+func TestDOTAttrs(t *testing.T) {
+	rel := Relation{
+		Name:     "foo",
+		Directed: true,
+		Style: Style{
+			Color:     "red",
+			ArrowHead: "diamond",
+			Line:      "dashed",
+		},
+	}
+
+	exp := map[string]string{
+		"dir":       "forward",
+		"color":     "red",
+		"arrowhead": "diamond",
+		"style":     "dashed",
+	}
+
+	a := rel.DOTAttrs()
+	if len(a.Keys()) != len(exp) {
+		t.Errorf("expected attributes: %d, got: %d", len(exp), len(a.Keys()))
+	}
+
+	for k, v := range exp {
+		if val := a.Get(k); val != v {
+			t.Errorf("expected %s: %s, got: %s", k, v, val)
+		}
+	}
+
+	if count := len((Relation{Name: "bar"}).DOTAttrs().Keys()); count != 0 {
+		t.Errorf("expected attributes: %d, got: %d", 0, count)
+	}
+}
@@ -0,0 +1,51 @@
+package store
+
+import (
+	"github.com/milosgajdos/kraph/pkg/api"
+	"github.com/milosgajdos/kraph/pkg/attrs"
+)
+
+const (
+	// LabelAttrPrefix prefixes node attributes copied from object labels
+	LabelAttrPrefix = "label:"
+	// AnnotationAttrPrefix prefixes node attributes copied from object annotations
+	AnnotationAttrPrefix = "annotation:"
+	// FieldAttrPrefix prefixes node attributes copied from object fields
+	FieldAttrPrefix = "field:"
+	// SourceAttr is node attribute which stores object API source
+	SourceAttr = "source"
+	// DirectedAttr is edge attribute which is true if the edge relation is directed
+	DirectedAttr = "directed"
+)
+
+// ObjectSource returns the API source of obj as string.
+// It returns empty string if the object source is unknown.
+func ObjectSource(obj api.Object) string {
+	if obj.Source() == nil {
+		return ""
+	}
+
+	return obj.Source().String()
+}
+
+// ObjectAttrs copies object source, labels, annotations and fields into a and returns it.
+// Attribute keys are prefixed with the prefix of their origin.
+func ObjectAttrs(a attrs.Attrs, obj api.Object) attrs.Attrs {
+	if src := ObjectSource(obj); len(src) > 0 {
+		a.Set(SourceAttr, src)
+	}
+
+	for k, v := range obj.Labels() {
+		a.Set(LabelAttrPrefix+k, v)
+	}
+
+	for k, v := range obj.Annotations() {
+		a.Set(AnnotationAttrPrefix+k, v)
+	}
+
+	for k, v := range obj.Fields() {
+		a.Set(FieldAttrPrefix+k, v)
+	}
+
+	return a
+}
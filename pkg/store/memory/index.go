@@ -0,0 +1,187 @@
+package memory
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/milosgajdos/kraph/pkg/api"
+	"github.com/milosgajdos/kraph/pkg/attrs"
+	"github.com/milosgajdos/kraph/pkg/store"
+)
+
+// index maps values to the set of UIDs of the entities which have them
+type index map[string]map[string]bool
+
+// add adds uid to the set of val
+func (i index) add(val, uid string) {
+	if i[val] == nil {
+		i[val] = make(map[string]bool)
+	}
+	i[val][uid] = true
+}
+
+// remove removes uid from the set of val
+func (i index) remove(val, uid string) {
+	if set, ok := i[val]; ok {
+		delete(set, uid)
+		if len(set) == 0 {
+			delete(i, val)
+		}
+	}
+}
+
+// uidIndex caches the sorted UIDs of the store entities.
+// The cache is reset whenever an entity is added to or removed from the store
+// and it is sorted again on the first read after that.
+type uidIndex struct {
+	// mu guards uids which are read with the store read lock held
+	mu sync.Mutex
+	// uids are the sorted UIDs; nil when the cache was reset
+	uids []string
+}
+
+// reset invalidates the cached UIDs.
+func (i *uidIndex) reset() {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.uids = nil
+}
+
+// sorted returns the sorted UIDs of the entities, sorting them with keys if the cache was reset.
+// The returned slice is never modified so it can be safely iterated after the store is modified.
+func (i *uidIndex) sorted(keys func() []string) []string {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if i.uids == nil {
+		uids := keys()
+		sort.Strings(uids)
+		i.uids = uids
+	}
+
+	return i.uids
+}
+
+// attrIndex maps attribute keys to attribute value indices
+type attrIndex map[string]index
+
+// add indexes all attributes a of the entity with the given uid
+func (i attrIndex) add(a attrs.Attrs, uid string) {
+	for _, k := range a.Keys() {
+		i.set(k, a.Get(k), uid)
+	}
+}
+
+// set indexes attribute k with value v of the entity with the given uid
+func (i attrIndex) set(k, v, uid string) {
+	if i[k] == nil {
+		i[k] = make(index)
+	}
+	i[k].add(v, uid)
+}
+
+// remove removes all attributes a of the entity with the given uid from index
+func (i attrIndex) remove(a attrs.Attrs, uid string) {
+	for _, k := range a.Keys() {
+		i.unset(k, a.Get(k), uid)
+	}
+}
+
+// unset removes attribute k with value v of the entity with the given uid from index
+func (i attrIndex) unset(k, v, uid string) {
+	if idx, ok := i[k]; ok {
+		idx.remove(v, uid)
+		if len(idx) == 0 {
+			delete(i, k)
+		}
+	}
+}
+
+// lookup returns the set of UIDs of entities which have all the attributes a.
+// The sets of the individual attributes are intersected.
+func (i attrIndex) lookup(a attrs.Attrs) map[string]bool {
+	var result map[string]bool
+
+	for _, k := range a.Keys() {
+		set := i[k][a.Get(k)]
+		if result == nil {
+			result = make(map[string]bool, len(set))
+			for uid := range set {
+				result[uid] = true
+			}
+			continue
+		}
+
+		for uid := range result {
+			if !set[uid] {
+				delete(result, uid)
+			}
+		}
+	}
+
+	return result
+}
+
+// nodeIndex indexes graph nodes
+type nodeIndex struct {
+	ns     index
+	kind   index
+	name   index
+	source index
+	attrs  attrIndex
+}
+
+func newNodeIndex() *nodeIndex {
+	return &nodeIndex{
+		ns:     make(index),
+		kind:   make(index),
+		name:   make(index),
+		source: make(index),
+		attrs:  make(attrIndex),
+	}
+}
+
+// add indexes node of API object obj
+func (i *nodeIndex) add(obj api.Object, node *Node) {
+	uid := node.UID()
+
+	i.ns.add(obj.Namespace(), uid)
+	i.kind.add(obj.Resource().Kind(), uid)
+	i.name.add(obj.Name(), uid)
+	i.source.add(store.ObjectSource(obj), uid)
+	i.attrs.add(node.Attrs(), uid)
+}
+
+// remove removes node of API object obj from index
+func (i *nodeIndex) remove(obj api.Object, node *Node) {
+	uid := node.UID()
+
+	i.ns.remove(obj.Namespace(), uid)
+	i.kind.remove(obj.Resource().Kind(), uid)
+	i.name.remove(obj.Name(), uid)
+	i.source.remove(store.ObjectSource(obj), uid)
+	i.attrs.remove(node.Attrs(), uid)
+}
+
+// lineIndex indexes graph lines.
+// Line relations are indexed via the "relation" attribute.
+type lineIndex struct {
+	attrs attrIndex
+}
+
+func newLineIndex() *lineIndex {
+	return &lineIndex{
+		attrs: make(attrIndex),
+	}
+}
+
+// add indexes line
+func (i *lineIndex) add(line *Line) {
+	i.attrs.add(line.Attrs(), line.UID())
+}
+
+// remove removes line from index
+func (i *lineIndex) remove(line *Line) {
+	i.attrs.remove(line.Attrs(), line.UID())
+}
@@ -0,0 +1,296 @@
+package memory
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/milosgajdos/kraph/pkg/api/gen"
+	"github.com/milosgajdos/kraph/pkg/attrs"
+	"github.com/milosgajdos/kraph/pkg/query"
+	"github.com/milosgajdos/kraph/pkg/store"
+	"github.com/milosgajdos/kraph/pkg/uuid"
+)
+
+// newLargeMemory generates a store with the given number of nodes spread across
+// namespaces and kinds, each node linked to its predecessor.
+func newLargeMemory(nodes int) (*Memory, error) {
+	m, err := NewStore("testID", store.NewOptions())
+	if err != nil {
+		return nil, err
+	}
+
+	kinds := []string{"pod", "service", "deployment", "replicaset", "configmap"}
+
+	var prev store.Entity
+
+	for i := 0; i < nodes; i++ {
+		kind := kinds[i%len(kinds)]
+		ns := fmt.Sprintf("ns%d", i%50)
+		res := gen.NewResource(kind+"s", kind, "", "v1", true)
+		obj := gen.NewMockObject(fmt.Sprintf("uid%d", i), fmt.Sprintf("%s%d", kind, i), ns, res,
+			gen.Labels(map[string]string{"app": fmt.Sprintf("app%d", i%100)}))
+
+		node, err := m.Add(obj, store.NewAddOptions())
+		if err != nil {
+			return nil, err
+		}
+
+		if prev != nil {
+			opts := store.NewLinkOptions()
+			opts.Relation = fmt.Sprintf("rel%d", i%10)
+			if _, err := m.Link(prev, node, opts); err != nil {
+				return nil, err
+			}
+		}
+		prev = node
+	}
+
+	return m, nil
+}
+
+func TestIndexQueryNodes(t *testing.T) {
+	m, err := newLargeMemory(1000)
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+
+	a := attrs.New()
+	a.Set(store.LabelAttrPrefix+"app", "app5")
+
+	tests := []struct {
+		q   *query.Query
+		exp int
+	}{
+		{query.Build().Entity(query.Node).Namespace("ns1"), 20},
+		{query.Build().Entity(query.Node).Namespace("ns1").Kind("service"), 20},
+		{query.Build().Entity(query.Node).Namespace("ns2").Kind("service"), 0},
+		{query.Build().Entity(query.Node).Kind("pod").Name("pod5"), 1},
+		{query.Build().Entity(query.Node).Attrs(a), 10},
+		{query.Build().Entity(query.Node).Attrs(a).Kind("pod"), 10},
+		{query.Build().Entity(query.Node).Namespace("ns1", query.StringEqFunc("ns1")), 20},
+		{query.Build().Entity(query.Node).Namespace("nonEx"), 0},
+	}
+
+	for i, test := range tests {
+		nodes, err := m.Query(test.q)
+		if err != nil {
+			t.Errorf("test %d: failed to query nodes: %v", i, err)
+			continue
+		}
+
+		if len(nodes) != test.exp {
+			t.Errorf("test %d: expected nodes: %d, got: %d", i, test.exp, len(nodes))
+		}
+	}
+
+	if _, err := m.Query(query.Build().Entity(query.Node).Kind(1)); !errors.Is(err, query.ErrInvalidKind) {
+		t.Errorf("expected error: %v, got: %v", query.ErrInvalidKind, err)
+	}
+}
+
+func TestIndexMaintenance(t *testing.T) {
+	m, err := newLargeMemory(100)
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+
+	a := attrs.New()
+	a.Set("relation", "rel3")
+
+	q := query.Build().Entity(query.Edge).Attrs(a)
+
+	edges, err := m.Query(q)
+	if err != nil {
+		t.Fatalf("failed to query edges: %v", err)
+	}
+
+	if exp := 10; len(edges) != exp {
+		t.Fatalf("expected edges: %d, got: %d", exp, len(edges))
+	}
+
+	if _, err := m.Delete(edges[0], store.NewDelOptions()); err != nil {
+		t.Fatalf("failed to delete edge: %v", err)
+	}
+
+	opts := store.NewUpdateOptions()
+	opts.Attrs.Set("relation", "rel0")
+	if _, err := m.Update(edges[1], opts); err != nil {
+		t.Fatalf("failed to update edge: %v", err)
+	}
+
+	edges, err = m.Query(q)
+	if err != nil {
+		t.Fatalf("failed to query edges: %v", err)
+	}
+
+	if exp := 8; len(edges) != exp {
+		t.Errorf("expected edges: %d, got: %d", exp, len(edges))
+	}
+
+	nodes, err := m.Query(query.Build().Entity(query.Node).Name("pod0"))
+	if err != nil || len(nodes) != 1 {
+		t.Fatalf("failed to query node: %v", err)
+	}
+
+	if _, err := m.Delete(nodes[0], store.NewDelOptions()); err != nil {
+		t.Fatalf("failed to delete node: %v", err)
+	}
+
+	nodes, err = m.Query(query.Build().Entity(query.Node).Name("pod0"))
+	if err != nil {
+		t.Fatalf("failed to query node: %v", err)
+	}
+
+	if len(nodes) != 0 {
+		t.Errorf("expected nodes: %d, got: %d", 0, len(nodes))
+	}
+}
+
+func TestPlanNodes(t *testing.T) {
+	m, err := newLargeMemory(1000)
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+
+	tests := []struct {
+		q   *query.Query
+		exp int
+	}{
+		// namespace index has 20 nodes, kind index 200
+		{query.Build().Namespace("ns1").Kind("service"), 20},
+		{query.Build().Kind("service").Name("service1"), 1},
+		{query.Build().Kind("service"), 200},
+		{query.Build().Namespace("nonEx").Kind("service"), 0},
+		// no index can be used
+		{query.Build().Kind("service", query.StringEqFunc("service")), -1},
+	}
+
+	for i, test := range tests {
+		set, err := m.planNodes(test.q.Matcher())
+		if err != nil {
+			t.Errorf("test %d: failed to plan query: %v", i, err)
+			continue
+		}
+
+		if test.exp < 0 {
+			if set != nil {
+				t.Errorf("test %d: expected full scan, got: %d candidates", i, len(set))
+			}
+			continue
+		}
+
+		if len(set) != test.exp {
+			t.Errorf("test %d: expected candidates: %d, got: %d", i, test.exp, len(set))
+		}
+	}
+}
+
+func TestPlanUID(t *testing.T) {
+	m, err := newLargeMemory(10)
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+
+	edges, err := m.Edges("uid0", "uid1")
+	if err != nil || len(edges) != 1 {
+		t.Fatalf("failed to get edges: %v", err)
+	}
+	euid := edges[0].UID()
+
+	tests := []struct {
+		q   *query.Query
+		exp int
+		err error
+	}{
+		{query.Build().Entity(query.Node).UID(uuid.NewFromString("uid5")), 1, nil},
+		{query.Build().Entity(query.Node).UID(uuid.NewFromString("nonEx")), 0, nil},
+		{query.Build().Entity(query.Edge).UID(uuid.NewFromString(euid)), 1, nil},
+		{query.Build().Entity(query.Edge).UID(uuid.NewFromString("nonEx")), 0, nil},
+		{query.Build().Entity(query.Node).UID("uid5"), 0, query.ErrInvalidUID},
+		{query.Build().Entity(query.Edge).UID(euid), 0, query.ErrInvalidUID},
+	}
+
+	for i, test := range tests {
+		res, err := m.Query(test.q)
+		if !errors.Is(err, test.err) {
+			t.Errorf("test %d: expected error: %v, got: %v", i, test.err, err)
+			continue
+		}
+
+		if len(res) != test.exp {
+			t.Errorf("test %d: expected results: %d, got: %d", i, test.exp, len(res))
+		}
+	}
+}
+
+func benchmarkQueryNode(b *testing.B, nodes int, q *query.Query) {
+	m, err := newLargeMemory(nodes)
+	if err != nil {
+		b.Fatalf("failed to create store: %v", err)
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := m.QueryNode(q); err != nil {
+			b.Fatalf("failed to query nodes: %v", err)
+		}
+	}
+}
+
+func BenchmarkQueryNode(b *testing.B) {
+	for _, nodes := range []int{1000, 10000, 100000} {
+		b.Run(fmt.Sprintf("indexed-%d", nodes), func(b *testing.B) {
+			q := query.Build().Namespace("ns1").Kind("pod").Name("pod50")
+			benchmarkQueryNode(b, nodes, q)
+		})
+
+		b.Run(fmt.Sprintf("scan-%d", nodes), func(b *testing.B) {
+			q := query.Build().
+				Namespace("ns1", query.StringEqFunc("ns1")).
+				Kind("pod", query.StringEqFunc("pod")).
+				Name("pod50", query.StringEqFunc("pod50"))
+			benchmarkQueryNode(b, nodes, q)
+		})
+	}
+}
+
+func BenchmarkQueryLine(b *testing.B) {
+	for _, nodes := range []int{1000, 10000, 100000} {
+		a := attrs.New()
+		a.Set("relation", "rel1")
+
+		b.Run(fmt.Sprintf("indexed-%d", nodes), func(b *testing.B) {
+			m, err := newLargeMemory(nodes)
+			if err != nil {
+				b.Fatalf("failed to create store: %v", err)
+			}
+
+			q := query.Build().Attrs(a)
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := m.QueryLine(q); err != nil {
+					b.Fatalf("failed to query lines: %v", err)
+				}
+			}
+		})
+
+		b.Run(fmt.Sprintf("scan-%d", nodes), func(b *testing.B) {
+			m, err := newLargeMemory(nodes)
+			if err != nil {
+				b.Fatalf("failed to create store: %v", err)
+			}
+
+			q := query.Build().Attrs(a, query.HasAttrsFunc(a))
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := m.QueryLine(q); err != nil {
+					b.Fatalf("failed to query lines: %v", err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,308 @@
+package memory
+
+import (
+	"sort"
+
+	"github.com/milosgajdos/kraph/pkg/api"
+	"github.com/milosgajdos/kraph/pkg/query"
+	"github.com/milosgajdos/kraph/pkg/store"
+	"github.com/milosgajdos/kraph/pkg/uuid"
+)
+
+// sortedKeys returns the sorted keys of set
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// nodeUIDs returns the UIDs of all the store nodes.
+// It must be called with the store lock held.
+func (m *Memory) nodeUIDs() []string {
+	uids := make([]string, 0, len(m.nodes))
+	for uid := range m.nodes {
+		uids = append(uids, uid)
+	}
+
+	return uids
+}
+
+// lineUIDs returns the UIDs of all the store lines.
+// It must be called with the store lock held.
+func (m *Memory) lineUIDs() []string {
+	uids := make([]string, 0, len(m.lines))
+	for uid := range m.lines {
+		uids = append(uids, uid)
+	}
+
+	return uids
+}
+
+// page tracks query result pagination
+type page struct {
+	offset int
+	limit  int
+	count  int
+}
+
+// done returns true if the page limit has been reached
+func (p *page) done() bool {
+	return p.limit > 0 && p.count >= p.limit
+}
+
+// accept returns true if the next matched result belongs to the page
+func (p *page) accept() bool {
+	if p.offset > 0 {
+		p.offset--
+		return false
+	}
+	p.count++
+	return true
+}
+
+// nodeIterator iterates over the store nodes matching a query.
+// It iterates the sorted node UIDs cached by the store
+// or the sorted UIDs of the nodes found in the query indices
+// and copies the nodes one at a time as the iteration progresses.
+// Nodes deleted from the store during the iteration are skipped.
+type nodeIterator struct {
+	m     *Memory
+	match *query.Match
+	uids  []string
+	pos   int
+	page  page
+	node  *Node
+}
+
+// newNodeIterator returns a new iterator over the nodes matching q.
+// It must be called with the store lock held.
+func (m *Memory) newNodeIterator(q *query.Query) (*nodeIterator, error) {
+	match := q.Matcher()
+
+	candidates, err := m.planNodes(match)
+	if err != nil {
+		return nil, err
+	}
+
+	var uids []string
+	if candidates != nil {
+		uids = sortedKeys(candidates)
+	} else {
+		uids = m.nuids.sorted(m.nodeUIDs)
+	}
+
+	return &nodeIterator{
+		m:     m,
+		match: match,
+		uids:  uids,
+		page:  page{offset: match.Offset(), limit: match.Limit()},
+	}, nil
+}
+
+// Next advances the iterator to the next matching node.
+func (it *nodeIterator) Next() bool {
+	it.m.mu.RLock()
+	defer it.m.mu.RUnlock()
+
+	return it.next()
+}
+
+// next advances the iterator to the next matching node.
+// It must be called with the store lock held.
+func (it *nodeIterator) next() bool {
+	it.node = nil
+
+	for !it.page.done() && it.pos < len(it.uids) {
+		node, ok := it.m.nodes[it.uids[it.pos]]
+		it.pos++
+		if !ok {
+			continue
+		}
+
+		obj := node.Metadata().Get("object").(api.Object)
+
+		if !matchNode(it.match, node, obj) || !it.page.accept() {
+			continue
+		}
+
+		it.node = copyNode(node, obj)
+		return true
+	}
+
+	return false
+}
+
+// Node returns the current node
+func (it *nodeIterator) Node() store.Node {
+	if it.node == nil {
+		return nil
+	}
+	return it.node
+}
+
+// Err returns iteration error.
+// Iterating in-memory nodes never fails.
+func (it *nodeIterator) Err() error {
+	return nil
+}
+
+// Close stops the iteration
+func (it *nodeIterator) Close() error {
+	it.pos = len(it.uids)
+	it.node = nil
+	return nil
+}
+
+// matchNode returns true if node of API object obj matches the query
+func matchNode(match *query.Match, node *Node, obj api.Object) bool {
+	return match.UIDVal(obj.UID()) &&
+		match.NamespaceVal(obj.Namespace()) &&
+		match.SourceVal(store.ObjectSource(obj)) &&
+		match.KindVal(obj.Resource().Kind()) &&
+		match.GroupVal(obj.Resource().Group()) &&
+		match.VersionVal(obj.Resource().Version()) &&
+		match.NameVal(obj.Name()) &&
+		match.AttrsVal(node.Attrs()) &&
+		match.MetadataVal(node.Metadata())
+}
+
+// lineIterator iterates over the store lines matching a query.
+// It iterates the sorted line UIDs cached by the store
+// or the sorted UIDs of the lines found in the query indices
+// and copies the lines one at a time as the iteration progresses.
+// Lines deleted from the store during the iteration are skipped.
+type lineIterator struct {
+	m     *Memory
+	match *query.Match
+	uids  []string
+	pos   int
+	page  page
+	line  *Line
+}
+
+// newLineIterator returns a new iterator over the lines matching q.
+// It must be called with the store lock held.
+func (m *Memory) newLineIterator(q *query.Query) (*lineIterator, error) {
+	match := q.Matcher()
+
+	candidates, err := m.planLines(match)
+	if err != nil {
+		return nil, err
+	}
+
+	var uids []string
+	if candidates != nil {
+		uids = sortedKeys(candidates)
+	} else {
+		uids = m.luids.sorted(m.lineUIDs)
+	}
+
+	return &lineIterator{
+		m:     m,
+		match: match,
+		uids:  uids,
+		page:  page{offset: match.Offset(), limit: match.Limit()},
+	}, nil
+}
+
+// Next advances the iterator to the next matching edge.
+func (it *lineIterator) Next() bool {
+	it.m.mu.RLock()
+	defer it.m.mu.RUnlock()
+
+	return it.next()
+}
+
+// next advances the iterator to the next matching line.
+// It must be called with the store lock held.
+func (it *lineIterator) next() bool {
+	it.line = nil
+
+	for !it.page.done() && it.pos < len(it.uids) {
+		l, ok := it.m.lines[it.uids[it.pos]]
+		it.pos++
+		if !ok {
+			continue
+		}
+
+		if !matchLine(it.match, l) || !it.page.accept() {
+			continue
+		}
+
+		it.line = copyLine(l, l.from, l.to)
+		return true
+	}
+
+	return false
+}
+
+// Edge returns the current edge
+func (it *lineIterator) Edge() store.Edge {
+	if it.line == nil {
+		return nil
+	}
+	return it.line.Edge
+}
+
+// Err returns iteration error.
+// Iterating in-memory lines never fails.
+func (it *lineIterator) Err() error {
+	return nil
+}
+
+// Close stops the iteration
+func (it *lineIterator) Close() error {
+	it.pos = len(it.uids)
+	it.line = nil
+	return nil
+}
+
+// matchLine returns true if line l matches the query
+func matchLine(match *query.Match, l *Line) bool {
+	we := l.Edge
+
+	return match.UIDVal(uuid.NewFromString(we.UID())) &&
+		match.WeightVal(we.Weight()) &&
+		match.AttrsVal(we.Attrs()) &&
+		match.MetadataVal(we.Metadata())
+}
+
+// IterNodes returns an iterator over all the nodes in the store.
+// The nodes are iterated in the order of their UIDs.
+func (m *Memory) IterNodes() (store.NodeIterator, error) {
+	return m.QueryNodes(query.Build())
+}
+
+// QueryNodes returns an iterator over the nodes matching q.
+// The nodes are iterated in the order of their UIDs
+// and paginated using the query limit and offset.
+func (m *Memory) QueryNodes(q *query.Query) (store.NodeIterator, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	it, err := m.newNodeIterator(q)
+	if err != nil {
+		return nil, err
+	}
+
+	return it, nil
+}
+
+// QueryEdges returns an iterator over the edges matching q.
+// The edges are iterated in the order of their UIDs
+// and paginated using the query limit and offset.
+func (m *Memory) QueryEdges(q *query.Query) (store.EdgeIterator, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	it, err := m.newLineIterator(q)
+	if err != nil {
+		return nil, err
+	}
+
+	return it, nil
+}
@@ -0,0 +1,187 @@
+package memory
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/milosgajdos/kraph/pkg/query"
+	"github.com/milosgajdos/kraph/pkg/store"
+)
+
+func iterNodeUIDs(t *testing.T, it store.NodeIterator) []string {
+	defer it.Close()
+
+	var uids []string
+	for it.Next() {
+		uids = append(uids, it.Node().UID())
+	}
+
+	if err := it.Err(); err != nil {
+		t.Fatalf("failed to iterate nodes: %v", err)
+	}
+
+	return uids
+}
+
+func TestIterNodes(t *testing.T) {
+	m, err := newLargeMemory(100)
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+
+	it, err := m.IterNodes()
+	if err != nil {
+		t.Fatalf("failed to create iterator: %v", err)
+	}
+
+	if uids := iterNodeUIDs(t, it); len(uids) != 100 {
+		t.Errorf("expected nodes: %d, got: %d", 100, len(uids))
+	}
+}
+
+func TestQueryNodesPagination(t *testing.T) {
+	m, err := newLargeMemory(100)
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+
+	it, err := m.QueryNodes(query.Build().Kind("pod"))
+	if err != nil {
+		t.Fatalf("failed to create iterator: %v", err)
+	}
+
+	all := iterNodeUIDs(t, it)
+	if len(all) != 20 {
+		t.Fatalf("expected nodes: %d, got: %d", 20, len(all))
+	}
+
+	var paged []string
+	for offset := 0; offset < len(all); offset += 7 {
+		it, err := m.QueryNodes(query.Build().Kind("pod").Offset(offset).Limit(7))
+		if err != nil {
+			t.Fatalf("failed to create iterator: %v", err)
+		}
+		paged = append(paged, iterNodeUIDs(t, it)...)
+	}
+
+	if !reflect.DeepEqual(all, paged) {
+		t.Errorf("expected nodes: %v, got: %v", all, paged)
+	}
+
+	nodes, err := m.QueryNode(query.Build().Kind("pod").Offset(18).Limit(5))
+	if err != nil {
+		t.Fatalf("failed to query nodes: %v", err)
+	}
+
+	if len(nodes) != 2 {
+		t.Errorf("expected nodes: %d, got: %d", 2, len(nodes))
+	}
+}
+
+func TestQueryNodesDelete(t *testing.T) {
+	m, err := newLargeMemory(10)
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+
+	it, err := m.IterNodes()
+	if err != nil {
+		t.Fatalf("failed to create iterator: %v", err)
+	}
+	defer it.Close()
+
+	if !it.Next() {
+		t.Fatal("expected nodes to iterate")
+	}
+
+	node, err := m.Node("uid9")
+	if err != nil {
+		t.Fatalf("failed to get node: %v", err)
+	}
+
+	if _, err := m.Delete(node, store.NewDelOptions()); err != nil {
+		t.Fatalf("failed to delete node: %v", err)
+	}
+
+	count := 1
+	for it.Next() {
+		if it.Node().UID() == "uid9" {
+			t.Errorf("iterated deleted node")
+		}
+		count++
+	}
+
+	if count != 9 {
+		t.Errorf("expected nodes: %d, got: %d", 9, count)
+	}
+}
+
+func TestIterNodesAdd(t *testing.T) {
+	m, err := newLargeMemory(10)
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+
+	it, err := m.IterNodes()
+	if err != nil {
+		t.Fatalf("failed to create iterator: %v", err)
+	}
+	before := iterNodeUIDs(t, it)
+
+	if _, err := m.Add(newMockObject("uid00", "name00", "ns"), store.NewAddOptions()); err != nil {
+		t.Fatalf("failed to add node: %v", err)
+	}
+
+	it, err = m.IterNodes()
+	if err != nil {
+		t.Fatalf("failed to create iterator: %v", err)
+	}
+	after := iterNodeUIDs(t, it)
+
+	if len(after) != len(before)+1 {
+		t.Fatalf("expected nodes: %d, got: %d", len(before)+1, len(after))
+	}
+
+	if !sort.StringsAreSorted(after) {
+		t.Errorf("expected sorted nodes, got: %v", after)
+	}
+}
+
+func TestQueryEdgesPagination(t *testing.T) {
+	m, err := newLargeMemory(100)
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+
+	tests := []struct {
+		q   *query.Query
+		exp int
+	}{
+		{query.Build(), 99},
+		{query.Build().Limit(10), 10},
+		{query.Build().Offset(95), 4},
+		{query.Build().Offset(95).Limit(2), 2},
+		{query.Build().Offset(100), 0},
+	}
+
+	for i, test := range tests {
+		it, err := m.QueryEdges(test.q)
+		if err != nil {
+			t.Fatalf("test %d: failed to create iterator: %v", i, err)
+		}
+
+		count := 0
+		for it.Next() {
+			if it.Edge() == nil {
+				t.Errorf("test %d: got nil edge", i)
+			}
+			count++
+		}
+		it.Close()
+
+		if count != test.exp {
+			t.Errorf("test %d: expected edges: %d, got: %d", i, test.exp, count)
+		}
+	}
+}
@@ -2,9 +2,12 @@ package memory
 
 import (
 	"github.com/milosgajdos/kraph/pkg/attrs"
+	"github.com/milosgajdos/kraph/pkg/relation"
 	"github.com/milosgajdos/kraph/pkg/store/entity"
 	"gonum.org/v1/gonum/graph"
 	"gonum.org/v1/gonum/graph/encoding"
+	"gonum.org/v1/gonum/graph/iterator"
+	"gonum.org/v1/gonum/graph/multi"
 )
 
 // Line implements graph.WeightedLine
@@ -103,3 +106,50 @@ func (l *Line) Attributes() []encoding.Attribute {
 
 	return attrs
 }
+
+// dotLine is a line styled by its relation in DOT output
+type dotLine struct {
+	*Line
+	relations *relation.Registry
+}
+
+// Attributes implements store.DOTAttrs.
+// The line attributes override the DOT style of its relation.
+func (l dotLine) Attributes() []encoding.Attribute {
+	attrs := l.Line.Attributes()
+
+	r, ok := l.relations.Get(l.Attrs().Get("relation"))
+	if !ok {
+		return attrs
+	}
+
+	style := r.DOTAttrs()
+
+	var styled []encoding.Attribute
+	for _, k := range style.Keys() {
+		if l.Attrs().Get(k) == "" {
+			styled = append(styled, encoding.Attribute{Key: k, Value: style.Get(k)})
+		}
+	}
+
+	return append(styled, attrs...)
+}
+
+// dotGraph is the DOT view of the store graph which
+// styles its lines by the registered relations
+type dotGraph struct {
+	*multi.WeightedUndirectedGraph
+	relations *relation.Registry
+}
+
+// Lines returns the styled lines between the nodes with the given IDs
+func (g dotGraph) Lines(uid, vid int64) graph.Lines {
+	var lines []graph.Line
+
+	it := g.WeightedUndirectedGraph.Lines(uid, vid)
+	for it.Next() {
+		lines = append(lines, dotLine{Line: it.Line().(*Line), relations: g.relations})
+	}
+
+	return iterator.NewOrderedLines(lines)
+}
@@ -2,7 +2,9 @@ package memory
 
 import (
 	"fmt"
+	"sort"
 	"strings"
+	"sync"
 
 	"github.com/milosgajdos/kraph/pkg/api"
 	"github.com/milosgajdos/kraph/pkg/attrs"
@@ -16,11 +18,16 @@ import (
 	"gonum.org/v1/gonum/graph/encoding"
 	"gonum.org/v1/gonum/graph/encoding/dot"
 	"gonum.org/v1/gonum/graph/multi"
-	"gonum.org/v1/gonum/graph/traverse"
 )
 
-// Memory is in-memory graph store
+// Memory is in-memory graph store.
+// It is safe for concurrent use by multiple goroutines.
+// The entities it returns are never modified by the store: Update
+// replaces them with their updated copies. The entities must not be
+// modified directly while the store is in use: use Update instead.
 type Memory struct {
+	// mu guards all the fields below
+	mu sync.RWMutex
 	// g is in-memory graph
 	g *multi.WeightedUndirectedGraph
 	// id is the store id
@@ -29,257 +36,537 @@ type Memory struct {
 	nodes map[string]*Node
 	// lines maps api.Object links to graph Edges
 	lines map[string]*Line
+	// nindex indexes graph nodes
+	nindex *nodeIndex
+	// lindex indexes graph lines
+	lindex *lineIndex
+	// nuids caches the sorted node UIDs
+	nuids uidIndex
+	// luids caches the sorted line UIDs
+	luids uidIndex
+	// version is incremented on every store write
+	version uint64
 	// options are store options
 	opts store.Options
 }
 
+// dotID returns the DOT ID of the node of API object obj.
+// Objects with known API source have their DOT IDs prefixed with the source.
+func dotID(obj api.Object) string {
+	parts := []string{
+		obj.Resource().Version(),
+		obj.Namespace(),
+		obj.Resource().Kind(),
+		obj.Name(),
+	}
+
+	if src := store.ObjectSource(obj); len(src) > 0 {
+		parts = append([]string{src}, parts...)
+	}
+
+	return strings.Join(parts, "/")
+}
+
 // NewStore creates new in-memory store and returns it
 func NewStore(id string, opts store.Options) (*Memory, error) {
 	return &Memory{
-		g:     multi.NewWeightedUndirectedGraph(),
-		id:    id,
-		nodes: make(map[string]*Node),
-		lines: make(map[string]*Line),
-		opts:  opts,
+		g:      multi.NewWeightedUndirectedGraph(),
+		id:     id,
+		nodes:  make(map[string]*Node),
+		lines:  make(map[string]*Line),
+		nindex: newNodeIndex(),
+		lindex: newLineIndex(),
+		opts:   opts,
 	}, nil
 }
 
 // ID returns store ID
-func (m Memory) ID() string {
+func (m *Memory) ID() string {
 	return m.id
 }
 
 // Options returns store options
-func (m Memory) Options() store.Options {
+func (m *Memory) Options() store.Options {
 	return m.opts
 }
 
 // Add adds obj to the store and returns it
 func (m *Memory) Add(obj api.Object, opts store.AddOptions) (store.Entity, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.add(obj, opts)
+}
+
+// add adds obj to the store and returns its node.
+// It must be called with the store lock held.
+func (m *Memory) add(obj api.Object, opts store.AddOptions) (*Node, error) {
 	uid := obj.UID().String()
 
 	if node, ok := m.nodes[uid]; ok {
 		return node, nil
 	}
 
-	var entOpts []entity.Option
+	if obj.Resource() == nil {
+		return nil, errors.ErrMissingResource
+	}
+
+	node := newNode(m.g.NewNode().ID(), obj, opts)
+
+	m.g.AddNode(node)
+
+	m.nodes[uid] = node
+	m.nindex.add(obj, node)
+	m.nuids.reset()
+	m.version++
+
+	return node, nil
+}
+
+// newNode returns a new node with the given id of API object obj
+func newNode(id int64, obj api.Object, opts store.AddOptions) *Node {
+	dotid := dotID(obj)
 
 	attrs := attrs.New()
+	attrs.Set("name", dotid)
+	store.ObjectAttrs(attrs, obj)
+
 	if opts.Attrs != nil {
-		entOpts = append(entOpts, entity.Attrs(attrs))
+		for _, k := range opts.Attrs.Keys() {
+			attrs.Set(k, opts.Attrs.Get(k))
+		}
 	}
 
 	metadata := metadata.New()
 	if opts.Metadata != nil {
-		entOpts = append(entOpts, entity.Metadata(metadata))
+		for _, k := range opts.Metadata.Keys() {
+			metadata.Set(k, opts.Metadata.Get(k))
+		}
 	}
+	metadata.Set("object", obj)
 
-	if obj.Resource() == nil {
-		return nil, errors.ErrMissingResource
+	entOpts := []entity.Option{
+		entity.Attrs(attrs),
+		entity.Metadata(metadata),
 	}
 
-	dotid := strings.Join([]string{
-		obj.Resource().Version(),
-		obj.Namespace(),
-		obj.Resource().Kind(),
-		obj.Name()}, "/")
-	attrs.Set("name", dotid)
-
-	n := m.g.NewNode()
-
-	node := NewNode(n.ID(), uid, dotid, entOpts...)
-
-	node.Metadata().Set("object", obj)
-
-	m.g.AddNode(node)
+	return NewNode(id, obj.UID().String(), dotid, entOpts...)
+}
 
-	m.nodes[uid] = node
+// Update updates attributes and metadata of entity e with the values in opts
+// and returns the updated entity. It returns error if the entity does not exist.
+// Update is copy-on-write: the entity is replaced by its updated copy so
+// the entities which have been returned by the store are never modified.
+func (m *Memory) Update(e store.Entity, opts store.UpdateOptions) (store.Entity, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 
-	return node, nil
+	return m.update(e, opts)
 }
 
-// Delete deletes entity e from the memory store
-func (m *Memory) Delete(e store.Entity, opts store.DelOptions) error {
+// update updates entity e with the values in opts and returns the updated entity.
+// It must be called with the store lock held.
+func (m *Memory) update(e store.Entity, opts store.UpdateOptions) (store.Entity, error) {
+	var ent store.Entity
+
 	switch v := e.(type) {
 	case store.Edge:
-		l, ok := m.lines[e.UID()]
+		l, ok := m.lines[v.UID()]
 		if !ok {
-			return fmt.Errorf("Edge Delete %s: %w", e.UID(), errors.ErrEdgeNotFound)
+			return nil, fmt.Errorf("Edge Update %s: %w", v.UID(), errors.ErrEdgeNotFound)
 		}
 
-		from, ok := m.nodes[v.From().UID()]
+		line := copyLine(l, l.from, l.to)
+		update(line.Edge, opts, m.lindex.attrs)
+
+		m.g.SetWeightedLine(line)
+		m.lines[line.UID()] = line
+
+		ent = line.Edge
+	case store.Node:
+		node, ok := m.nodes[v.UID()]
 		if !ok {
-			return fmt.Errorf("Edge Delete %s: %w", v.From().UID(), errors.ErrNodeNotFound)
+			return nil, fmt.Errorf("Node Update %s: %w", v.UID(), errors.ErrNodeNotFound)
+		}
+
+		n := copyNode(node, node.Metadata().Get("object").(api.Object))
+		update(n, opts, m.nindex.attrs)
+
+		m.replaceNode(node, n)
+
+		ent = n
+	default:
+		return nil, errors.ErrUnknownEntity
+	}
+
+	m.version++
+
+	return ent, nil
+}
+
+// update updates attributes and metadata of entity e with the values in opts.
+// The attributes of e are reindexed in idx.
+func update(e store.Entity, opts store.UpdateOptions, idx attrIndex) {
+	if opts.Attrs != nil {
+		for _, k := range opts.Attrs.Keys() {
+			idx.unset(k, e.Attrs().Get(k), e.UID())
+			e.Attrs().Set(k, opts.Attrs.Get(k))
+			idx.set(k, opts.Attrs.Get(k), e.UID())
 		}
+	}
 
-		to, ok := m.nodes[v.To().UID()]
+	if opts.Metadata != nil {
+		for _, k := range opts.Metadata.Keys() {
+			e.Metadata().Set(k, opts.Metadata.Get(k))
+		}
+	}
+}
+
+// replaceNode replaces node old with node n in the graph.
+// The lines of node old are replaced by their copies linking node n.
+// It must be called with the store lock held.
+func (m *Memory) replaceNode(old, n *Node) {
+	lines := make(map[string]*Line)
+
+	nodes := m.g.From(old.ID())
+	for nodes.Next() {
+		wls := m.g.WeightedLines(old.ID(), nodes.Node().ID())
+		for wls.Next() {
+			uid := wls.WeightedLine().(*Line).UID()
+			lines[uid] = m.lines[uid]
+		}
+	}
+
+	m.g.RemoveNode(old.ID())
+	m.g.AddNode(n)
+	m.nodes[n.UID()] = n
+
+	for uid, l := range lines {
+		from, to := l.from, l.to
+		if from.UID() == n.UID() {
+			from = n
+		}
+		if to.UID() == n.UID() {
+			to = n
+		}
+
+		line := copyLine(l, from, to)
+
+		m.g.SetWeightedLine(line)
+		m.lines[uid] = line
+	}
+}
+
+// Delete deletes entity e from the memory store and returns the removed entities.
+// Deleting a node deletes its edges and, depending on the cascade mode, the nodes it owns.
+func (m *Memory) Delete(e store.Entity, opts store.DelOptions) ([]store.Entity, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.delete(e, opts)
+}
+
+// delete deletes entity e from the store and returns the removed entities.
+// It must be called with the store lock held.
+func (m *Memory) delete(e store.Entity, opts store.DelOptions) ([]store.Entity, error) {
+	var removed []store.Entity
+
+	switch v := e.(type) {
+	case store.Edge:
+		l, ok := m.lines[v.UID()]
 		if !ok {
-			return fmt.Errorf("Edge Delete %s: %w", v.To().UID(), errors.ErrNodeNotFound)
+			return nil, fmt.Errorf("Edge Delete %s: %w", v.UID(), errors.ErrEdgeNotFound)
 		}
 
-		m.g.RemoveLine(from.ID(), to.ID(), l.ID())
-		delete(m.lines, e.UID())
+		m.removeLine(l)
+		removed = append(removed, l.Edge)
 	case store.Node:
 		node, ok := m.nodes[v.UID()]
 		if !ok {
-			return fmt.Errorf("Node Delete %s: %w", v.UID(), errors.ErrNodeNotFound)
+			return nil, fmt.Errorf("Node Delete %s: %w", v.UID(), errors.ErrNodeNotFound)
 		}
 
-		m.g.RemoveNode(node.ID())
-		delete(m.nodes, v.UID())
+		nodes, err := cascade([]*Node{node}, opts, m.linesOf)
+		if err != nil {
+			return nil, err
+		}
+
+		removed = m.removeNodes(nodes)
 	default:
-		return errors.ErrUnknownEntity
+		return nil, errors.ErrUnknownEntity
 	}
 
-	return nil
+	m.version++
+
+	return removed, nil
 }
 
-// QueryNode returns all the nodes that match given query.
-func (m *Memory) QueryNode(q *query.Query) ([]*Node, error) {
-	match := q.Matcher()
+// DeleteQuery deletes all the entities matching q from the memory store
+// and returns the removed entities. The matched nodes are deleted as if
+// they were deleted by Delete. No entity is deleted if any of the nodes can not be.
+func (m *Memory) DeleteQuery(q *query.Query, opts store.DelOptions) ([]store.Entity, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 
-	if quid := match.UID(); quid != nil {
-		if uid, ok := quid.Value().(uuid.UID); ok && len(uid.String()) > 0 {
-			if n, ok := m.nodes[uid.String()]; ok {
-				return []*Node{n}, nil
-			}
-		}
+	return m.deleteQuery(q, opts)
+}
+
+// deleteQuery deletes the entities matching q from the store and returns the removed entities.
+// It must be called with the store lock held.
+func (m *Memory) deleteQuery(q *query.Query, opts store.DelOptions) ([]store.Entity, error) {
+	e, err := queryEntity(q)
+	if err != nil {
+		return nil, err
 	}
 
-	var results []*Node
+	var removed []store.Entity
 
-	visit := func(n graph.Node) {
-		node := n.(*Node)
-		nodeObj := node.Metadata().Get("object").(api.Object)
+	switch e {
+	case query.Node:
+		it, err := m.newNodeIterator(q)
+		if err != nil {
+			return nil, fmt.Errorf("Node Delete: %w", err)
+		}
 
-		if match.NamespaceVal(nodeObj.Namespace()) {
-			if match.KindVal(nodeObj.Resource().Kind()) {
-				if match.NameVal(nodeObj.Name()) {
-					if !match.AttrsVal(node.Attrs()) {
-						return
-					}
+		var nodes []*Node
+		for it.next() {
+			nodes = append(nodes, m.nodes[it.node.UID()])
+		}
 
-					// create a deep copy of the matched node
-					attrs := attrs.New()
-					metadata := metadata.New()
+		if nodes, err = cascade(nodes, opts, m.linesOf); err != nil {
+			return nil, err
+		}
 
-					for _, k := range node.Attrs().Keys() {
-						attrs.Set(k, node.Attrs().Get(k))
-					}
+		removed = m.removeNodes(nodes)
+	case query.Edge:
+		it, err := m.newLineIterator(q)
+		if err != nil {
+			return nil, fmt.Errorf("Edge Delete: %w", err)
+		}
 
-					for _, k := range node.Metadata().Keys() {
-						metadata.Set(k, node.Metadata().Get(k))
-					}
+		var lines []*Line
+		for it.next() {
+			lines = append(lines, m.lines[it.line.UID()])
+		}
 
-					dotid := strings.Join([]string{
-						nodeObj.Resource().Version(),
-						nodeObj.Namespace(),
-						nodeObj.Resource().Kind(),
-						nodeObj.Name()}, "/")
-					attrs.Set("name", dotid)
+		for _, l := range lines {
+			m.removeLine(l)
+			removed = append(removed, l.Edge)
+		}
+	default:
+		return nil, errors.ErrUnknownEntity
+	}
+
+	if len(removed) > 0 {
+		m.version++
+	}
 
-					entOpts := []entity.Option{
-						entity.Metadata(metadata),
-						entity.Attrs(attrs),
-					}
+	return removed, nil
+}
 
-					n := NewNode(node.ID(), node.UID(), dotid, entOpts...)
+// cascade returns nodes along with all the nodes which must be deleted with them.
+// The lines of the nodes are looked up using linesOf.
+// It returns error if the nodes can not be deleted in the given cascade mode.
+func cascade(nodes []*Node, opts store.DelOptions, linesOf func(*Node, store.Direction) []*Line) ([]*Node, error) {
+	switch opts.Cascade {
+	case store.CascadeNone:
+		for _, node := range nodes {
+			if len(linesOf(node, store.DirBoth)) > 0 {
+				return nil, fmt.Errorf("Node Delete %s: %w", node.UID(), errors.ErrNodeHasEdges)
+			}
+		}
+	case store.CascadeOwned:
+		rel := opts.OwnRelation
+		if rel == "" {
+			rel = store.DefaultOwnRelation
+		}
 
-					results = append(results, n)
+		seen := make(map[string]bool)
+		for _, node := range nodes {
+			seen[node.UID()] = true
+		}
+
+		// owned nodes link to their owners
+		for i := 0; i < len(nodes); i++ {
+			for _, l := range linesOf(nodes[i], store.DirIn) {
+				if l.Attrs().Get("relation") != rel || seen[l.from.UID()] {
+					continue
 				}
+				seen[l.from.UID()] = true
+				nodes = append(nodes, l.from)
 			}
 		}
 	}
 
-	dfs := traverse.DepthFirst{
-		Visit: visit,
+	return nodes, nil
+}
+
+// removeNodes removes nodes along with all their lines
+// from the store and returns the removed entities.
+// It must be called with the store lock held.
+func (m *Memory) removeNodes(nodes []*Node) []store.Entity {
+	var removed []store.Entity
+
+	for _, node := range nodes {
+		for _, l := range m.linesOf(node, store.DirBoth) {
+			m.removeLine(l)
+			removed = append(removed, l.Edge)
+		}
 	}
 
-	dfs.WalkAll(m.g, nil, nil, func(graph.Node) {})
+	for _, node := range nodes {
+		m.g.RemoveNode(node.ID())
+		m.nindex.remove(node.Metadata().Get("object").(api.Object), node)
+		delete(m.nodes, node.UID())
+		m.nuids.reset()
+		removed = append(removed, node)
+	}
+
+	return removed
+}
+
+// removeLine removes line l from the store.
+// It must be called with the store lock held.
+func (m *Memory) removeLine(l *Line) {
+	m.g.RemoveLine(l.from.ID(), l.to.ID(), l.ID())
+	m.lindex.remove(l)
+	delete(m.lines, l.UID())
+	m.luids.reset()
+}
+
+// QueryNode returns all the nodes that match given query.
+// It looks up the candidate nodes in the most selective index
+// usable by the query or it scans all the nodes if there is none.
+func (m *Memory) QueryNode(q *query.Query) ([]*Node, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.queryNode(q)
+}
+
+// queryNode returns all the nodes that match given query.
+// It must be called with the store lock held.
+func (m *Memory) queryNode(q *query.Query) ([]*Node, error) {
+	it, err := m.newNodeIterator(q)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []*Node
+
+	for it.next() {
+		results = append(results, it.node)
+	}
 
 	return results, nil
 }
 
-// QueryEdge returns all the edges that match given query
-func (m *Memory) QueryLine(q *query.Query) ([]*Line, error) {
-	match := q.Matcher()
+// copyNode returns a deep copy of node of API object obj
+func copyNode(node *Node, obj api.Object) *Node {
+	attrs := attrs.New()
+	metadata := metadata.New()
 
-	var results []*Line
+	for _, k := range node.Attrs().Keys() {
+		attrs.Set(k, node.Attrs().Get(k))
+	}
 
-	if quid := match.UID(); quid != nil {
-		if uid, ok := quid.Value().(string); ok && len(uid) > 0 {
-			if l, ok := m.lines[uid]; ok {
-				return []*Line{l}, nil
-			}
-		}
+	for _, k := range node.Metadata().Keys() {
+		metadata.Set(k, node.Metadata().Get(k))
 	}
 
-	trav := func(e graph.Edge) bool {
-		from := e.From().(*Node)
-		to := e.To().(*Node)
+	dotid := dotID(obj)
+	attrs.Set("name", dotid)
 
-		if lines := m.g.WeightedLines(from.ID(), to.ID()); lines != nil {
-			for lines.Next() {
-				wl := lines.WeightedLine()
-				we := wl.(*Line).Edge
-				if match.WeightVal(we.Weight()) {
-					if !match.AttrsVal(we.Attrs()) {
-						continue
-					}
+	entOpts := []entity.Option{
+		entity.Metadata(metadata),
+		entity.Attrs(attrs),
+	}
 
-					attrs := attrs.New()
-					metadata := metadata.New()
+	return NewNode(node.ID(), node.UID(), dotid, entOpts...)
+}
 
-					for _, k := range we.Attrs().Keys() {
-						attrs.Set(k, we.Attrs().Get(k))
-					}
+// QueryLine returns all the lines that match given query.
+// It looks up the candidate lines in the most selective index
+// usable by the query or it scans all the lines if there is none.
+func (m *Memory) QueryLine(q *query.Query) ([]*Line, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
 
-					for _, k := range we.Metadata().Keys() {
-						metadata.Set(k, we.Metadata().Get(k))
-					}
+	return m.queryLine(q)
+}
 
-					opts := []entity.Option{
-						entity.Attrs(attrs),
-						entity.Metadata(metadata),
-						entity.Weight(we.Weight()),
-					}
+// queryLine returns all the lines that match given query.
+// It must be called with the store lock held.
+func (m *Memory) queryLine(q *query.Query) ([]*Line, error) {
+	it, err := m.newLineIterator(q)
+	if err != nil {
+		return nil, err
+	}
 
-					ent := NewLine(wl.ID(), we.UID(), we.UID(), from, to, opts...)
+	var results []*Line
 
-					results = append(results, ent)
-				}
-			}
-		}
+	for it.next() {
+		results = append(results, it.line)
+	}
+
+	return results, nil
+}
+
+// copyLine returns a deep copy of line l between the nodes from and to
+func copyLine(l *Line, from, to *Node) *Line {
+	we := l.Edge
+
+	attrs := attrs.New()
+	metadata := metadata.New()
 
-		return true
+	for _, k := range we.Attrs().Keys() {
+		attrs.Set(k, we.Attrs().Get(k))
 	}
 
-	dfs := traverse.DepthFirst{
-		Traverse: trav,
+	for _, k := range we.Metadata().Keys() {
+		metadata.Set(k, we.Metadata().Get(k))
 	}
 
-	dfs.WalkAll(m.g, nil, nil, func(graph.Node) {})
+	opts := []entity.Option{
+		entity.Attrs(attrs),
+		entity.Metadata(metadata),
+		entity.Weight(we.Weight()),
+		entity.Relation(we.Options().Relation),
+	}
 
-	return results, nil
+	return NewLine(l.ID(), we.UID(), l.DOTID(), from, to, opts...)
 }
 
-// Query queries the in-memory graph and returns the matched results.
-func (m *Memory) Query(q *query.Query) ([]store.Entity, error) {
+// queryEntity returns the entity queried by q
+func queryEntity(q *query.Query) (query.Entity, error) {
 	var e query.Entity
 
 	if m := q.Matcher().Entity(); m != nil {
 		var ok bool
 		e, ok = m.Value().(query.Entity)
 		if !ok {
-			return nil, errors.ErrInvalidEntity
+			return e, errors.ErrInvalidEntity
 		}
 	}
 
+	return e, nil
+}
+
+// Query queries the in-memory graph and returns the matched results.
+func (m *Memory) Query(q *query.Query) ([]store.Entity, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	e, err := queryEntity(q)
+	if err != nil {
+		return nil, err
+	}
+
 	var entities []store.Entity
 
 	switch e {
 	case query.Node:
-		nodes, err := m.QueryNode(q)
+		nodes, err := m.queryNode(q)
 		if err != nil {
 			return nil, fmt.Errorf("Node query: %w", err)
 		}
@@ -287,12 +574,12 @@ func (m *Memory) Query(q *query.Query) ([]store.Entity, error) {
 			entities = append(entities, node.Node)
 		}
 	case query.Edge:
-		edges, err := m.QueryLine(q)
+		edges, err := m.queryLine(q)
 		if err != nil {
 			return nil, fmt.Errorf("Edge query: %w", err)
 		}
 		for _, edge := range edges {
-			entities = append(entities, edge)
+			entities = append(entities, edge.Edge)
 		}
 	default:
 		return nil, errors.ErrUnknownEntity
@@ -304,6 +591,9 @@ func (m *Memory) Query(q *query.Query) ([]store.Entity, error) {
 // Node returns the node with the given ID if it exists
 // in the graph, and nil otherwise.
 func (m *Memory) Node(id string) (store.Node, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
 	if node, ok := m.nodes[id]; ok {
 		return node, nil
 	}
@@ -313,6 +603,9 @@ func (m *Memory) Node(id string) (store.Node, error) {
 
 // Nodes returns all the nodes in the graph.
 func (m *Memory) Nodes() ([]store.Node, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
 	graphNodes := graph.NodesOf(m.g.Nodes())
 
 	nodes := make([]store.Node, len(graphNodes))
@@ -324,9 +617,12 @@ func (m *Memory) Nodes() ([]store.Node, error) {
 	return nodes, nil
 }
 
-// Edges returns all the edges (lines) from u to v
-// if such edges exists and nil otherwise
+// Edges returns all the edges (lines) between u and v in the direction
+// they were linked in if such edges exists and nil otherwise
 func (m *Memory) Edges(uid, vid string) ([]store.Edge, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
 	from, ok := m.nodes[uid]
 	if !ok {
 		return nil, fmt.Errorf("Edges %s: %w", uid, errors.ErrNodeNotFound)
@@ -341,9 +637,10 @@ func (m *Memory) Edges(uid, vid string) ([]store.Edge, error) {
 
 	if lines := m.g.WeightedLines(from.ID(), to.ID()); lines != nil {
 		for lines.Next() {
-			wl := lines.WeightedLine()
-			we := wl.(*Line).Edge
-			edges = append(edges, we)
+			// undirected graph lines are reversed to start at the node uid
+			// so we look up the line in the direction it was linked in
+			l := m.lines[lines.WeightedLine().(*Line).UID()]
+			edges = append(edges, l.Edge)
 		}
 
 		return edges, nil
@@ -352,10 +649,132 @@ func (m *Memory) Edges(uid, vid string) ([]store.Edge, error) {
 	return nil, errors.ErrEdgeNotExist
 }
 
+// AllEdges returns an iterator over all the edges in the graph.
+// The edges are iterated in the order of their UIDs.
+func (m *Memory) AllEdges() (store.EdgeIterator, error) {
+	return m.QueryEdges(query.Build())
+}
+
+// linesOf returns the lines of node in the given direction sorted by their UIDs.
+// It must be called with the store lock held.
+func (m *Memory) linesOf(node *Node, dir store.Direction) []*Line {
+	var lines []*Line
+
+	peers := m.g.From(node.ID())
+	for peers.Next() {
+		wls := m.g.WeightedLines(node.ID(), peers.Node().ID())
+		for wls.Next() {
+			// undirected graph lines are reversed to start at the node
+			// so we look up the line in the direction it was linked in
+			l := m.lines[wls.WeightedLine().(*Line).UID()]
+
+			switch dir {
+			case store.DirOut:
+				if l.from.UID() != node.UID() {
+					continue
+				}
+			case store.DirIn:
+				if l.to.UID() != node.UID() {
+					continue
+				}
+			}
+
+			lines = append(lines, l)
+		}
+	}
+
+	sort.Slice(lines, func(i, j int) bool {
+		return lines[i].UID() < lines[j].UID()
+	})
+
+	return lines
+}
+
+// EdgesOf returns all the edges of node n in the given direction.
+// It returns error if the node does not exist.
+func (m *Memory) EdgesOf(n store.Node, dir store.Direction) ([]store.Edge, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	node, ok := m.nodes[n.UID()]
+	if !ok {
+		return nil, fmt.Errorf("EdgesOf %s: %w", n.UID(), errors.ErrNodeNotFound)
+	}
+
+	lines := m.linesOf(node, dir)
+
+	edges := make([]store.Edge, len(lines))
+	for i, l := range lines {
+		edges[i] = l.Edge
+	}
+
+	return edges, nil
+}
+
+// Neighbours returns all the nodes linked to node n sorted by their UIDs.
+// If any relations are given only the nodes linked via them are returned.
+// It returns error if the node does not exist.
+func (m *Memory) Neighbours(n store.Node, relations ...string) ([]store.Node, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	node, ok := m.nodes[n.UID()]
+	if !ok {
+		return nil, fmt.Errorf("Neighbours %s: %w", n.UID(), errors.ErrNodeNotFound)
+	}
+
+	rels := make(map[string]bool, len(relations))
+	for _, r := range relations {
+		rels[r] = true
+	}
+
+	seen := make(map[string]bool)
+	var nodes []store.Node
+
+	for _, l := range m.linesOf(node, store.DirBoth) {
+		if len(rels) > 0 && !rels[l.Attrs().Get("relation")] {
+			continue
+		}
+
+		peer := l.to
+		if peer.UID() == node.UID() {
+			peer = l.from
+		}
+
+		if !seen[peer.UID()] {
+			seen[peer.UID()] = true
+			nodes = append(nodes, peer)
+		}
+	}
+
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].UID() < nodes[j].UID()
+	})
+
+	return nodes, nil
+}
+
+// Degree returns the number of the edges of node n in the given direction.
+// It returns error if the node does not exist.
+func (m *Memory) Degree(n store.Node, dir store.Direction) (int, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	node, ok := m.nodes[n.UID()]
+	if !ok {
+		return 0, fmt.Errorf("Degree %s: %w", n.UID(), errors.ErrNodeNotFound)
+	}
+
+	return len(m.linesOf(node, dir)), nil
+}
+
 // Link creates a new edge between the nodes and returns it or it returns
-// an existing edge if the edges between the nodes already exists.
+// an existing edge if the edge of the same relation between the nodes already exists.
 // It returns error if either of the nodes does not exist in the graph.
 func (m *Memory) Link(from store.Node, to store.Node, opts store.LinkOptions) (store.Edge, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	f, ok := m.nodes[from.UID()]
 	if !ok {
 		return nil, fmt.Errorf("Link %s: %w", from.UID(), errors.ErrNodeNotFound)
@@ -366,16 +785,64 @@ func (m *Memory) Link(from store.Node, to store.Node, opts store.LinkOptions) (s
 		return nil, fmt.Errorf("Link %s: %w", to.UID(), errors.ErrNodeNotFound)
 	}
 
-	if we := m.g.WeightedEdge(f.ID(), t.ID()); we != nil {
-		if !opts.Line {
-			me := we.(multi.WeightedEdge)
-			if me.Next() {
-				wl := me.WeightedLine()
-				return wl.(*Line).Edge, nil
-			}
+	return m.link(f, t, opts, uuid.New().String()), nil
+}
+
+// link links the nodes with a new line with the given uid and returns its edge
+// or it returns an existing edge of the same relation between the nodes.
+// It must be called with the store lock held.
+func (m *Memory) link(f, t *Node, opts store.LinkOptions, uid string) store.Edge {
+	if !opts.Line {
+		if l := m.line(f, t, linkRelation(opts)); l != nil {
+			return l.Edge
+		}
+	}
+
+	entOpts, w := lineOptions(opts)
+
+	wl := m.g.NewWeightedLine(f, t, w)
+
+	line := NewLine(wl.ID(), uid, uid, f, t, entOpts...)
+
+	m.g.SetWeightedLine(line)
+
+	m.lines[uid] = line
+	m.lindex.add(line)
+	m.luids.reset()
+	m.version++
+
+	return line.Edge
+}
+
+// line returns the line of the given relation from node f to node t
+// or it returns nil if the nodes are not linked in the relation.
+// The line linking t to f in the same relation is not returned.
+// It must be called with the store lock held.
+func (m *Memory) line(f, t *Node, relation string) *Line {
+	wls := m.g.WeightedLines(f.ID(), t.ID())
+	for wls.Next() {
+		// undirected graph lines may be reversed
+		// so we look up the line in the direction it was linked in
+		l := m.lines[wls.WeightedLine().(*Line).UID()]
+		if l.from.UID() == f.UID() && l.Attrs().Get("relation") == relation {
+			return l
 		}
 	}
 
+	return nil
+}
+
+// linkRelation returns the relation of the link configured by opts
+func linkRelation(opts store.LinkOptions) string {
+	if opts.Attrs != nil && opts.Attrs.Get("relation") != "" {
+		return opts.Attrs.Get("relation")
+	}
+
+	return opts.Relation
+}
+
+// lineOptions returns the entity options and the weight of the line configured by opts
+func lineOptions(opts store.LinkOptions) ([]entity.Option, float64) {
 	var entOpts []entity.Option
 
 	attrs := attrs.New()
@@ -389,13 +856,16 @@ func (m *Memory) Link(from store.Node, to store.Node, opts store.LinkOptions) (s
 	metadata := metadata.New()
 	if opts.Metadata != nil {
 		for _, k := range opts.Metadata.Keys() {
-			metadata.Set(k, metadata.Get(k))
+			metadata.Set(k, opts.Metadata.Get(k))
 		}
 	}
 	entOpts = append(entOpts, entity.Metadata(metadata))
 
 	if len(opts.Relation) > 0 {
 		entOpts = append(entOpts, entity.Relation(opts.Relation))
+		if attrs.Get("relation") == "" {
+			attrs.Set("relation", opts.Relation)
+		}
 	}
 
 	w := opts.Weight
@@ -404,112 +874,111 @@ func (m *Memory) Link(from store.Node, to store.Node, opts store.LinkOptions) (s
 	}
 	entOpts = append(entOpts, entity.Weight(w))
 
-	wl := m.g.NewWeightedLine(f, t, w)
-
-	uid := uuid.New().String()
-
-	line := NewLine(wl.ID(), uid, uid, f, t, entOpts...)
-
-	m.g.SetWeightedLine(line)
-
-	m.lines[uid] = line
-
-	return line.Edge, nil
+	return entOpts, w
 }
 
-// SubGraph returns the subgraph of the node up to given depth
-func (m *Memory) SubGraph(n store.Node, depth int) (store.Graph, error) {
-	rootNode, ok := m.nodes[n.UID()]
+// SubGraph returns the subgraph of node n traversed according to opts.
+// The root node is always part of the subgraph. The subgraph contains
+// all the edges of the allowed relations between its nodes.
+func (m *Memory) SubGraph(n store.Node, opts store.SubGraphOptions) (store.Graph, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	root, ok := m.nodes[n.UID()]
 	if !ok {
 		return nil, errors.ErrNodeNotFound
 	}
 
-	s := &Memory{
-		g:     multi.NewWeightedUndirectedGraph(),
-		id:    "sub-" + m.id,
-		nodes: make(map[string]*Node),
-		lines: make(map[string]*Line),
+	rels := make(map[string]bool, len(opts.Relations))
+	for _, r := range opts.Relations {
+		rels[r] = true
 	}
 
-	var sgErr error
+	allowed := func(l *Line) bool {
+		return len(rels) == 0 || rels[l.Attrs().Get("relation")]
+	}
 
-	subnodes := make(map[int64]store.Node)
+	full := func(nodes []*Node) bool {
+		return opts.MaxNodes > 0 && len(nodes) >= opts.MaxNodes
+	}
 
-	visit := func(n graph.Node) {
-		vnode := n.(*Node)
+	nodes := []*Node{root}
+	depths := map[string]int{root.UID(): 0}
+	rejected := make(map[string]bool)
 
-		nodeAttrs := attrs.New()
-		for _, k := range vnode.Attrs().Keys() {
-			nodeAttrs.Set(k, vnode.Attrs().Get(k))
+	for i := 0; i < len(nodes) && !full(nodes); i++ {
+		node := nodes[i]
+		if depths[node.UID()] >= opts.Depth {
+			continue
 		}
 
-		nodeMetadata := metadata.New()
-		for _, k := range vnode.Metadata().Keys() {
-			nodeMetadata.Set(k, vnode.Metadata().Get(k))
+		for _, l := range m.linesOf(node, opts.Direction) {
+			if !allowed(l) {
+				continue
+			}
+
+			peer := l.to
+			if peer.UID() == node.UID() {
+				peer = l.from
+			}
+
+			if _, ok := depths[peer.UID()]; ok || rejected[peer.UID()] {
+				continue
+			}
+
+			if opts.NodeFilter != nil && !opts.NodeFilter(peer) {
+				rejected[peer.UID()] = true
+				continue
+			}
+
+			if full(nodes) {
+				break
+			}
+
+			depths[peer.UID()] = depths[node.UID()] + 1
+			nodes = append(nodes, peer)
 		}
+	}
 
-		obj := vnode.Metadata().Get("object").(api.Object)
-		opts := store.AddOptions{
-			Attrs:    nodeAttrs,
-			Metadata: nodeMetadata,
+	s, err := NewStore("sub-"+m.id, m.opts)
+	if err != nil {
+		return nil, err
+	}
+
+	subnodes := make(map[string]store.Node, len(nodes))
+
+	for _, node := range nodes {
+		obj := node.Metadata().Get("object").(api.Object)
+		addOpts := store.AddOptions{
+			Attrs:    node.Attrs(),
+			Metadata: node.Metadata(),
 		}
 
-		storeNode, err := s.Add(obj, opts)
+		subnode, err := s.Add(obj, addOpts)
 		if err != nil {
-			sgErr = err
-			return
+			return nil, fmt.Errorf("Subgraph: %w", err)
 		}
-
-		subnodes[n.ID()] = storeNode
+		subnodes[node.UID()] = subnode.(store.Node)
 	}
 
-	bfs := traverse.BreadthFirst{
-		Visit: visit,
-	}
+	for _, node := range nodes {
+		for _, l := range m.linesOf(node, store.DirOut) {
+			to, ok := subnodes[l.to.UID()]
+			if !ok || !allowed(l) {
+				continue
+			}
 
-	_ = bfs.Walk(m.g, rootNode, func(n graph.Node, d int) bool {
-		if d == depth {
-			return true
-		}
-		return false
-	})
+			we := l.Edge
+			linkOpts := store.LinkOptions{
+				Line:     true,
+				Weight:   we.Weight(),
+				Relation: we.Options().Relation,
+				Attrs:    we.Attrs(),
+				Metadata: we.Metadata(),
+			}
 
-	if sgErr != nil {
-		return nil, sgErr
-	}
-
-	for id, node := range subnodes {
-		nodes := s.g.From(id)
-		for nodes.Next() {
-			pnode := nodes.Node()
-			peer := pnode.(*Node)
-			if to, ok := subnodes[peer.ID()]; ok {
-				if lines := m.g.WeightedLines(id, pnode.ID()); lines != nil {
-					for lines.Next() {
-						wl := lines.WeightedLine()
-						we := wl.(*Line).Edge
-						attrs := attrs.New()
-						for _, k := range we.Attrs().Keys() {
-							attrs.Set(k, we.Attrs().Get(k))
-						}
-
-						metadata := metadata.New()
-						for _, k := range we.Metadata().Keys() {
-							metadata.Set(k, we.Metadata().Get(k))
-						}
-
-						opts := store.LinkOptions{
-							Line:     true,
-							Weight:   we.Weight(),
-							Attrs:    attrs,
-							Metadata: metadata,
-						}
-
-						if _, err := s.Link(node, to, opts); err != nil {
-							return nil, fmt.Errorf("Subgraph: %v", err)
-						}
-					}
-				}
+			if _, err := s.Link(subnodes[node.UID()], to, linkOpts); err != nil {
+				return nil, fmt.Errorf("Subgraph: %w", err)
 			}
 		}
 	}
@@ -544,7 +1013,15 @@ func (m *Memory) DOTAttributers() (graph, node, edge encoding.Attributer) {
 
 // DOT returns the GrapViz dot representation of kraph.
 func (m *Memory) DOT() (string, error) {
-	b, err := dot.MarshalMulti(m.g, "", "", "  ")
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var g graph.Multigraph = m.g
+	if m.opts.DOTOptions.Relations != nil {
+		g = dotGraph{WeightedUndirectedGraph: m.g, relations: m.opts.DOTOptions.Relations}
+	}
+
+	b, err := dot.MarshalMulti(g, "", "", "  ")
 	if err != nil {
 		return "", fmt.Errorf("failed to encode into DOT: %w", err)
 	}
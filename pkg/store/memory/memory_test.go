@@ -1,9 +1,12 @@
 package memory
 
 import (
+	"fmt"
 	"io/ioutil"
 	"math/big"
 	"reflect"
+	"strings"
+	"sync"
 	"testing"
 
 	goerr "errors"
@@ -16,8 +19,10 @@ import (
 	"github.com/milosgajdos/kraph/pkg/errors"
 	"github.com/milosgajdos/kraph/pkg/metadata"
 	"github.com/milosgajdos/kraph/pkg/query"
+	"github.com/milosgajdos/kraph/pkg/relation"
 	"github.com/milosgajdos/kraph/pkg/store"
 	"github.com/milosgajdos/kraph/pkg/store/entity"
+	"github.com/milosgajdos/kraph/pkg/store/storetest"
 	"github.com/milosgajdos/kraph/pkg/uuid"
 )
 
@@ -168,6 +173,165 @@ func TestAddNode(t *testing.T) {
 	}
 }
 
+func TestAddNodeAttrs(t *testing.T) {
+	m, err := NewStore("testID", store.NewOptions())
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+
+	res := gen.NewResource("res", "fooKind", "fooGroup", "v1", true)
+	obj := gen.NewMockObject("fooUID", "fooName", "fooNs", res,
+		gen.Labels(map[string]string{"app": "foo"}),
+		gen.Annotations(map[string]string{"owner": "bar"}),
+		gen.Fields(map[string]string{"phase": "Running"}),
+	)
+
+	node, err := m.Add(obj, store.NewAddOptions())
+	if err != nil {
+		t.Fatalf("failed adding object: %v", err)
+	}
+
+	exp := map[string]string{
+		"name":                               "v1/fooNs/fooKind/fooName",
+		store.LabelAttrPrefix + "app":        "foo",
+		store.AnnotationAttrPrefix + "owner": "bar",
+		store.FieldAttrPrefix + "phase":      "Running",
+	}
+
+	for k, v := range exp {
+		if val := node.Attrs().Get(k); val != v {
+			t.Errorf("expected attribute %s: %s, got: %s", k, v, val)
+		}
+	}
+}
+
+func TestAddLinkOptions(t *testing.T) {
+	m, err := NewStore("testID", store.NewOptions())
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+
+	addOpts := store.NewAddOptions()
+	addOpts.Attrs.Set("color", "red")
+	addOpts.Metadata.Set("foo", 10)
+
+	node1, err := m.Add(newMockObject("fooUID", "fooName", "fooNs"), addOpts)
+	if err != nil {
+		t.Fatalf("failed adding object: %v", err)
+	}
+
+	node2, err := m.Add(newMockObject("foo2UID", "foo2Name", "fooNs"), store.NewAddOptions())
+	if err != nil {
+		t.Fatalf("failed adding object: %v", err)
+	}
+
+	q := query.Build().
+		Entity(query.Node).
+		Attrs(addOpts.Attrs, query.HasAttrsFunc(addOpts.Attrs)).
+		Metadata(addOpts.Metadata, query.HasMetadataFunc(addOpts.Metadata))
+
+	nodes, err := m.Query(q)
+	if err != nil {
+		t.Fatalf("failed to query nodes: %v", err)
+	}
+
+	if len(nodes) != 1 || nodes[0].UID() != node1.UID() {
+		t.Fatalf("expected node %s, got: %v", node1.UID(), nodes)
+	}
+
+	if name := nodes[0].Attrs().Get("name"); name != "v1/fooNs/fooKind/fooName" {
+		t.Errorf("expected name attribute, got: %s", name)
+	}
+
+	if _, ok := nodes[0].Metadata().Get("object").(api.Object); !ok {
+		t.Errorf("expected object metadata")
+	}
+
+	linkOpts := store.NewLinkOptions()
+	linkOpts.Attrs.Set("relation", "foo")
+	linkOpts.Metadata.Set("bar", "baz")
+
+	if _, err := m.Link(node1, node2, linkOpts); err != nil {
+		t.Fatalf("failed to link nodes: %v", err)
+	}
+
+	q = query.Build().
+		Entity(query.Edge).
+		Attrs(linkOpts.Attrs, query.HasAttrsFunc(linkOpts.Attrs)).
+		Metadata(linkOpts.Metadata, query.HasMetadataFunc(linkOpts.Metadata))
+
+	edges, err := m.Query(q)
+	if err != nil {
+		t.Fatalf("failed to query edges: %v", err)
+	}
+
+	if len(edges) != 1 {
+		t.Fatalf("expected edges: %d, got: %d", 1, len(edges))
+	}
+
+	if val := edges[0].Metadata().Get("bar"); val != "baz" {
+		t.Errorf("expected metadata: %s, got: %v", "baz", val)
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	m, err := NewStore("testID", store.NewOptions())
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+
+	node1, err := m.Add(newMockObject("fooUID", "fooName", "fooNs"), store.NewAddOptions())
+	if err != nil {
+		t.Fatalf("failed adding object: %v", err)
+	}
+
+	node2, err := m.Add(newMockObject("foo2UID", "foo2Name", "fooNs"), store.NewAddOptions())
+	if err != nil {
+		t.Fatalf("failed adding object: %v", err)
+	}
+
+	edge, err := m.Link(node1, node2, store.NewLinkOptions())
+	if err != nil {
+		t.Fatalf("failed to link nodes: %v", err)
+	}
+
+	opts := store.NewUpdateOptions()
+	opts.Attrs.Set("color", "blue")
+	opts.Metadata.Set("foo", "bar")
+
+	for _, e := range []store.Entity{node1, edge} {
+		if _, err := m.Update(e, opts); err != nil {
+			t.Fatalf("failed to update entity %s: %v", e.UID(), err)
+		}
+	}
+
+	for _, e := range []query.Entity{query.Node, query.Edge} {
+		q := query.Build().
+			Entity(e).
+			Attrs(opts.Attrs, query.HasAttrsFunc(opts.Attrs)).
+			Metadata(opts.Metadata, query.HasMetadataFunc(opts.Metadata))
+
+		ents, err := m.Query(q)
+		if err != nil {
+			t.Fatalf("failed to query entities: %v", err)
+		}
+
+		if len(ents) != 1 {
+			t.Errorf("expected entities: %d, got: %d", 1, len(ents))
+		}
+	}
+
+	nodeX := entity.NewNode("nonEx")
+
+	if _, err := m.Update(nodeX, opts); !goerr.Is(err, errors.ErrNodeNotFound) {
+		t.Errorf("expected: %v, got: %v", errors.ErrNodeNotFound, err)
+	}
+
+	if _, err := m.Update(entity.NewEdge("nonEx", nodeX, nodeX), opts); !goerr.Is(err, errors.ErrEdgeNotFound) {
+		t.Errorf("expected: %v, got: %v", errors.ErrEdgeNotFound, err)
+	}
+}
+
 func TestGetNode(t *testing.T) {
 	m, err := NewStore("testID", store.NewOptions())
 	if err != nil {
@@ -264,6 +428,19 @@ func TestLink(t *testing.T) {
 		t.Errorf("expected %#v, got: %#v", exEdge, edge)
 	}
 
+	// linking already linked nodes with a different relation must create a new edge
+	opts := store.NewLinkOptions()
+	opts.Relation = "fooRel"
+
+	relEdge, err := m.Link(node1, node2, opts)
+	if err != nil {
+		t.Errorf("failed to link %s to %s: %v", node1.UID(), node2.UID(), err)
+	}
+
+	if relEdge.UID() == edge.UID() {
+		t.Errorf("expected new edge for relation %s", opts.Relation)
+	}
+
 	if _, err := m.Edges("", node2.UID()); !goerr.Is(err, errors.ErrNodeNotFound) {
 		t.Errorf("expected %v edge, got: %#v", errors.ErrNodeNotFound, err)
 	}
@@ -273,6 +450,135 @@ func TestLink(t *testing.T) {
 	}
 }
 
+func TestAllEdges(t *testing.T) {
+	m, err := newTestMemory()
+	if err != nil {
+		t.Fatalf("failed to create new memory store: %v", err)
+	}
+
+	it, err := m.AllEdges()
+	if err != nil {
+		t.Fatalf("failed to get edges: %v", err)
+	}
+	defer it.Close()
+
+	count := 0
+	for it.Next() {
+		count++
+	}
+
+	if exp := 6; count != exp {
+		t.Errorf("expected edges: %d, got: %d", exp, count)
+	}
+}
+
+func TestEdgesOf(t *testing.T) {
+	m, err := newTestMemory()
+	if err != nil {
+		t.Fatalf("failed to create new memory store: %v", err)
+	}
+
+	tests := []struct {
+		uid string
+		dir store.Direction
+		exp int
+	}{
+		{"fooNs/fooKind/foo1", store.DirBoth, 4},
+		{"fooNs/fooKind/foo1", store.DirOut, 3},
+		{"fooNs/fooKind/foo1", store.DirIn, 1},
+		{"rndNs/rndKind/rnd2", store.DirOut, 2},
+		{"rndNs/rndKind/rnd2", store.DirIn, 1},
+		{"fooNs/fooKind/foo2", store.DirBoth, 0},
+	}
+
+	for _, test := range tests {
+		node, err := m.Node(test.uid)
+		if err != nil {
+			t.Fatalf("failed to get node %s: %v", test.uid, err)
+		}
+
+		edges, err := m.EdgesOf(node, test.dir)
+		if err != nil {
+			t.Fatalf("failed to get edges of %s: %v", test.uid, err)
+		}
+
+		if len(edges) != test.exp {
+			t.Errorf("%s: expected edges: %d, got: %d", test.uid, test.exp, len(edges))
+		}
+
+		for _, e := range edges {
+			if (test.dir == store.DirOut && e.From().UID() != test.uid) ||
+				(test.dir == store.DirIn && e.To().UID() != test.uid) {
+				t.Errorf("%s: unexpected edge direction: %s -> %s", test.uid, e.From().UID(), e.To().UID())
+			}
+		}
+
+		degree, err := m.Degree(node, test.dir)
+		if err != nil {
+			t.Fatalf("failed to get degree of %s: %v", test.uid, err)
+		}
+
+		if degree != test.exp {
+			t.Errorf("%s: expected degree: %d, got: %d", test.uid, test.exp, degree)
+		}
+	}
+
+	node := newMockObject("nonEx", "nonEx", "nonEx")
+	n := NewNode(100, node.UID().String(), "nonEx")
+
+	if _, err := m.EdgesOf(n, store.DirBoth); !goerr.Is(err, errors.ErrNodeNotFound) {
+		t.Errorf("expected error: %v, got: %v", errors.ErrNodeNotFound, err)
+	}
+
+	if _, err := m.Degree(n, store.DirBoth); !goerr.Is(err, errors.ErrNodeNotFound) {
+		t.Errorf("expected error: %v, got: %v", errors.ErrNodeNotFound, err)
+	}
+}
+
+func TestNeighbours(t *testing.T) {
+	m, err := newTestMemory()
+	if err != nil {
+		t.Fatalf("failed to create new memory store: %v", err)
+	}
+
+	node, err := m.Node("fooNs/fooKind/foo1")
+	if err != nil {
+		t.Fatalf("failed to get node: %v", err)
+	}
+
+	tests := []struct {
+		relations []string
+		exp       []string
+	}{
+		{nil, []string{"fooNs/fooKind/foo4", "fooNs/fooKind/foo5", "global/barKind/bar5", "rndNs/rndKind/rnd2"}},
+		{[]string{"foo-foo"}, []string{"fooNs/fooKind/foo4", "fooNs/fooKind/foo5"}},
+		{[]string{"foo-bar", "rnd-foo"}, []string{"global/barKind/bar5", "rndNs/rndKind/rnd2"}},
+		{[]string{"nonEx"}, nil},
+	}
+
+	for _, test := range tests {
+		nodes, err := m.Neighbours(node, test.relations...)
+		if err != nil {
+			t.Fatalf("failed to get neighbours: %v", err)
+		}
+
+		var uids []string
+		for _, n := range nodes {
+			uids = append(uids, n.UID())
+		}
+
+		if !reflect.DeepEqual(uids, test.exp) {
+			t.Errorf("relations %v: expected neighbours: %v, got: %v", test.relations, test.exp, uids)
+		}
+	}
+}
+
+func TestGraphConformance(t *testing.T) {
+	storetest.TestGraph(t, func() (store.Store, error) {
+		return NewStore("testID", store.NewOptions())
+	})
+}
+
 func TestDelete(t *testing.T) {
 	m, err := NewStore("testID", store.NewOptions())
 	if err != nil {
@@ -298,7 +604,7 @@ func TestDelete(t *testing.T) {
 		t.Errorf("failed to link %s to %s: %v", node1.UID(), node2.UID(), err)
 	}
 
-	if err := m.Delete(edge, store.NewDelOptions()); err != nil {
+	if _, err := m.Delete(edge, store.NewDelOptions()); err != nil {
 		t.Errorf("failed to delete edge: %v", err)
 	}
 
@@ -311,7 +617,7 @@ func TestDelete(t *testing.T) {
 		t.Errorf("expected edges: %d, got: %d", 0, len(edges))
 	}
 
-	if err := m.Delete(node1, store.NewDelOptions()); err != nil {
+	if _, err := m.Delete(node1, store.NewDelOptions()); err != nil {
 		t.Errorf("failed to delete node: %v", err)
 	}
 
@@ -321,17 +627,140 @@ func TestDelete(t *testing.T) {
 
 	nodeX := entity.NewNode("nonEx")
 
-	if err := m.Delete(nodeX, store.NewDelOptions()); !goerr.Is(err, errors.ErrNodeNotFound) {
+	if _, err := m.Delete(nodeX, store.NewDelOptions()); !goerr.Is(err, errors.ErrNodeNotFound) {
 		t.Errorf("expected: %v, got: %v", errors.ErrNodeNotFound, err)
 	}
 
 	edgeX := entity.NewEdge("foo", nodeX, nodeX)
 
-	if err := m.Delete(edgeX, store.NewDelOptions()); !goerr.Is(err, errors.ErrEdgeNotFound) {
+	if _, err := m.Delete(edgeX, store.NewDelOptions()); !goerr.Is(err, errors.ErrEdgeNotFound) {
 		t.Errorf("expected: %v, got: %v", errors.ErrNodeNotFound, err)
 	}
 }
 
+// newOwnerMemory returns a store with the following nodes and edges:
+// owned1 -isOwned-> owner, owned2 -isOwned-> owned1, user -uses-> owner
+func newOwnerMemory() (*Memory, error) {
+	m, err := NewStore("testID", store.NewOptions())
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := make(map[string]store.Entity)
+	for _, uid := range []string{"owner", "owned1", "owned2", "user"} {
+		node, err := m.Add(newMockObject(uid, uid, "fooNs"), store.NewAddOptions())
+		if err != nil {
+			return nil, err
+		}
+		nodes[uid] = node
+	}
+
+	for _, l := range []struct{ from, to, rel string }{
+		{"owned1", "owner", store.DefaultOwnRelation},
+		{"owned2", "owned1", store.DefaultOwnRelation},
+		{"user", "owner", "uses"},
+	} {
+		opts := store.NewLinkOptions()
+		opts.Relation = l.rel
+		if _, err := m.Link(nodes[l.from].(store.Node), nodes[l.to].(store.Node), opts); err != nil {
+			return nil, err
+		}
+	}
+
+	return m, nil
+}
+
+func TestDeleteCascade(t *testing.T) {
+	tests := []struct {
+		cascade  store.Cascade
+		err      error
+		removed  int
+		expNodes int
+		expLines int
+	}{
+		{store.CascadeNone, errors.ErrNodeHasEdges, 0, 4, 3},
+		{store.CascadeEdges, nil, 3, 3, 1},
+		{store.CascadeOwned, nil, 6, 1, 0},
+	}
+
+	for _, test := range tests {
+		m, err := newOwnerMemory()
+		if err != nil {
+			t.Fatalf("failed to create store: %v", err)
+		}
+
+		owner, err := m.Node("owner")
+		if err != nil {
+			t.Fatalf("failed to get node: %v", err)
+		}
+
+		opts := store.NewDelOptions()
+		opts.Cascade = test.cascade
+
+		removed, err := m.Delete(owner, opts)
+		if !goerr.Is(err, test.err) {
+			t.Errorf("cascade %d: expected error: %v, got: %v", test.cascade, test.err, err)
+		}
+
+		if len(removed) != test.removed {
+			t.Errorf("cascade %d: expected removed: %d, got: %d", test.cascade, test.removed, len(removed))
+		}
+
+		if len(m.nodes) != test.expNodes {
+			t.Errorf("cascade %d: expected nodes: %d, got: %d", test.cascade, test.expNodes, len(m.nodes))
+		}
+
+		if len(m.lines) != test.expLines {
+			t.Errorf("cascade %d: expected lines: %d, got: %d", test.cascade, test.expLines, len(m.lines))
+		}
+	}
+}
+
+func TestDeleteQuery(t *testing.T) {
+	m, err := newOwnerMemory()
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+
+	a := attrs.New()
+	a.Set("relation", store.DefaultOwnRelation)
+
+	removed, err := m.DeleteQuery(query.Build().Entity(query.Edge).Attrs(a), store.NewDelOptions())
+	if err != nil {
+		t.Fatalf("failed to delete edges: %v", err)
+	}
+
+	if len(removed) != 2 {
+		t.Errorf("expected removed: %d, got: %d", 2, len(removed))
+	}
+
+	opts := store.NewDelOptions()
+	opts.Cascade = store.CascadeNone
+
+	q := query.Build().Entity(query.Node).Name("owned", query.StringPrefixFunc("owned"))
+
+	removed, err = m.DeleteQuery(q, opts)
+	if err != nil {
+		t.Fatalf("failed to delete nodes: %v", err)
+	}
+
+	if len(removed) != 2 {
+		t.Errorf("expected removed: %d, got: %d", 2, len(removed))
+	}
+
+	if _, err := m.DeleteQuery(query.Build().Entity(query.Node), opts); !goerr.Is(err, errors.ErrNodeHasEdges) {
+		t.Errorf("expected error: %v, got: %v", errors.ErrNodeHasEdges, err)
+	}
+
+	if len(m.nodes) != 2 {
+		t.Errorf("expected nodes: %d, got: %d", 2, len(m.nodes))
+	}
+
+	if _, err := m.DeleteQuery(query.Build().Entity("garbage"), opts); !goerr.Is(err, errors.ErrInvalidEntity) {
+		t.Errorf("expected error: %v, got: %v", errors.ErrInvalidEntity, err)
+	}
+}
+
 func TestQueryUnknownEntity(t *testing.T) {
 	m, err := NewStore("testID", store.NewOptions())
 	if err != nil {
@@ -506,7 +935,7 @@ func TestSubgraph(t *testing.T) {
 	fooNode := NewNode(100, "foo", "bar")
 
 	// subgraph of non-existent node should return error
-	if _, err := m.SubGraph(fooNode, 10); err != errors.ErrNodeNotFound {
+	if _, err := m.SubGraph(fooNode, store.SubGraphOptions{Depth: 10}); err != errors.ErrNodeNotFound {
 		t.Errorf("expected: %v, got: %v", errors.ErrNodeNotFound, err)
 	}
 
@@ -523,7 +952,10 @@ func TestSubgraph(t *testing.T) {
 	}
 
 	for _, tc := range testCases {
-		g, err := m.SubGraph(node, tc.depth)
+		opts := store.NewSubGraphOptions()
+		opts.Depth = tc.depth
+
+		g, err := m.SubGraph(node, opts)
 		if err != nil {
 			t.Errorf("failed to query subgraph: %v", err)
 			continue
@@ -541,6 +973,74 @@ func TestSubgraph(t *testing.T) {
 	}
 }
 
+func TestSubgraphOptions(t *testing.T) {
+	m, err := newTestMemory()
+	if err != nil {
+		t.Fatalf("failed to create new memory store: %v", err)
+	}
+
+	root, err := m.Node("fooNs/fooKind/foo1")
+	if err != nil {
+		t.Fatalf("failed to get node: %v", err)
+	}
+
+	tests := []struct {
+		opts     store.SubGraphOptions
+		expNodes int
+		expEdges int
+	}{
+		// all the nodes and edges
+		{store.SubGraphOptions{Depth: 100}, 6, 6},
+		// foo4, foo5, bar5 and the edges from foo1 to them
+		{store.SubGraphOptions{Depth: 1, Direction: store.DirOut}, 4, 3},
+		// rnd2, bar5 via rnd2 and all the edges between them and foo1
+		{store.SubGraphOptions{Depth: 2, Direction: store.DirIn}, 3, 3},
+		// foo4 and foo5
+		{store.SubGraphOptions{Depth: 100, Relations: []string{"foo-foo"}}, 3, 2},
+		{store.SubGraphOptions{Depth: 100, MaxNodes: 3}, 3, -1},
+		{store.SubGraphOptions{
+			Depth: 100,
+			NodeFilter: func(n store.Node) bool {
+				return n.Metadata().Get("object").(api.Object).Resource().Kind() == "fooKind"
+			}}, 3, 2},
+	}
+
+	for i, test := range tests {
+		g, err := m.SubGraph(root, test.opts)
+		if err != nil {
+			t.Fatalf("test %d: failed to get subgraph: %v", i, err)
+		}
+
+		nodes, err := g.Nodes()
+		if err != nil {
+			t.Fatalf("test %d: failed to get nodes: %v", i, err)
+		}
+
+		if len(nodes) != test.expNodes {
+			t.Errorf("test %d: expected nodes: %d, got: %d", i, test.expNodes, len(nodes))
+		}
+
+		if test.expEdges < 0 {
+			continue
+		}
+
+		it, err := g.AllEdges()
+		if err != nil {
+			t.Fatalf("test %d: failed to get edges: %v", i, err)
+		}
+
+		edges := 0
+		for it.Next() {
+			edges++
+		}
+		it.Close()
+
+		if edges != test.expEdges {
+			t.Errorf("test %d: expected edges: %d, got: %d", i, test.expEdges, edges)
+		}
+	}
+}
+
 func TestDOT(t *testing.T) {
 	id := "testID"
 	m, err := NewStore(id, store.NewOptions())
@@ -561,3 +1061,195 @@ func TestDOT(t *testing.T) {
 		t.Errorf("expected non-empty DOT graph string")
 	}
 }
+
+func TestDOTRelations(t *testing.T) {
+	r, err := relation.NewRegistry(relation.Relation{Name: "fooRel", Style: relation.Style{Color: "red"}})
+	if err != nil {
+		t.Fatalf("failed to create relation registry: %v", err)
+	}
+
+	m, err := NewStore("testID", store.Options{DOTOptions: store.DOTOptions{Relations: r}})
+	if err != nil {
+		t.Fatalf("failed to create new memory store: %v", err)
+	}
+
+	from, err := m.Add(newMockObject("fooUID", "foo", "fooNs"), store.NewAddOptions())
+	if err != nil {
+		t.Fatalf("failed to add node: %v", err)
+	}
+
+	to, err := m.Add(newMockObject("barUID", "bar", "fooNs"), store.NewAddOptions())
+	if err != nil {
+		t.Fatalf("failed to add node: %v", err)
+	}
+
+	opts := store.NewLinkOptions()
+	opts.Relation = "fooRel"
+
+	if _, err := m.Link(from.(store.Node), to.(store.Node), opts); err != nil {
+		t.Fatalf("failed to link nodes: %v", err)
+	}
+
+	dot, err := m.DOT()
+	if err != nil {
+		t.Fatalf("failed to get DOT graph: %v", err)
+	}
+
+	if !strings.Contains(dot, "color=red") {
+		t.Errorf("expected styled edge, got: %s", dot)
+	}
+
+	// registry changes apply to the graph which has already been built
+	if err := r.Register(relation.Relation{Name: "fooRel", Style: relation.Style{Color: "blue"}}); err != nil {
+		t.Fatalf("failed to register relation: %v", err)
+	}
+
+	dot, err = m.DOT()
+	if err != nil {
+		t.Fatalf("failed to get DOT graph: %v", err)
+	}
+
+	if !strings.Contains(dot, "color=blue") || strings.Contains(dot, "color=red") {
+		t.Errorf("expected restyled edge, got: %s", dot)
+	}
+}
+
+func TestConcurrentAccess(t *testing.T) {
+	m, err := newTestMemory()
+	if err != nil {
+		t.Fatalf("failed to create new memory store: %v", err)
+	}
+
+	root, err := m.Node("fooNs/fooKind/foo1")
+	if err != nil {
+		t.Fatalf("failed to get node: %v", err)
+	}
+
+	const workers, iterations = 8, 50
+
+	var wg sync.WaitGroup
+	errChan := make(chan error, workers*5)
+
+	for w := 0; w < workers; w++ {
+		wg.Add(5)
+
+		// writers update the shared root node
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < iterations; i++ {
+				opts := store.NewUpdateOptions()
+				opts.Attrs.Set("writer", fmt.Sprintf("%d-%d", w, i))
+				if _, err := m.Update(root, opts); err != nil {
+					errChan <- err
+					return
+				}
+			}
+		}(w)
+
+		// writers add, link, update and delete their own nodes
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < iterations; i++ {
+				uid := fmt.Sprintf("w%d-%d", w, i)
+				node, err := m.Add(newMockObject(uid, uid, "fooNs"), store.NewAddOptions())
+				if err != nil {
+					errChan <- err
+					return
+				}
+
+				edge, err := m.Link(root, node, store.NewLinkOptions())
+				if err != nil {
+					errChan <- err
+					return
+				}
+
+				opts := store.NewUpdateOptions()
+				opts.Attrs.Set("iteration", fmt.Sprintf("%d", i))
+				if _, err := m.Update(edge, opts); err != nil {
+					errChan <- err
+					return
+				}
+
+				if i%2 == 0 {
+					if _, err := m.Delete(edge, store.NewDelOptions()); err != nil {
+						errChan <- err
+						return
+					}
+				}
+			}
+		}(w)
+
+		// readers query nodes and edges
+		go func() {
+			defer wg.Done()
+			for i := 0; i < iterations; i++ {
+				for _, e := range []query.Entity{query.Node, query.Edge} {
+					if _, err := m.Query(query.Build().Entity(e).Namespace("fooNs")); err != nil {
+						errChan <- err
+						return
+					}
+				}
+			}
+		}()
+
+		// readers build subgraphs
+		go func() {
+			defer wg.Done()
+			for i := 0; i < iterations; i++ {
+				if _, err := m.SubGraph(root, store.SubGraphOptions{Depth: 2}); err != nil {
+					errChan <- err
+					return
+				}
+			}
+		}()
+
+		// readers list and encode the graph and read the entity attributes
+		go func() {
+			defer wg.Done()
+			for i := 0; i < iterations; i++ {
+				nodes, err := m.Nodes()
+				if err != nil {
+					errChan <- err
+					return
+				}
+				for _, n := range nodes {
+					_ = n.Attrs().Get("writer")
+				}
+				edges, err := m.EdgesOf(root, store.DirBoth)
+				if err != nil {
+					errChan <- err
+					return
+				}
+				for _, e := range edges {
+					_ = e.Attrs().Get("iteration")
+					_ = e.From().Attrs().Get("writer")
+				}
+				if _, err := m.DOT(); err != nil {
+					errChan <- err
+					return
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+	close(errChan)
+
+	for err := range errChan {
+		t.Errorf("concurrent access failed: %v", err)
+	}
+
+	nodes, err := m.Nodes()
+	if err != nil {
+		t.Fatalf("failed to get nodes: %v", err)
+	}
+
+	objects, err := makeAPIObjects()
+	if err != nil {
+		t.Fatalf("failed to make API objects: %v", err)
+	}
+
+	if exp := len(objects) + workers*iterations; len(nodes) != exp {
+		t.Errorf("expected nodes: %d, got: %d", exp, len(nodes))
+	}
+}
@@ -0,0 +1,119 @@
+package memory
+
+import (
+	"github.com/milosgajdos/kraph/pkg/attrs"
+	"github.com/milosgajdos/kraph/pkg/query"
+	"github.com/milosgajdos/kraph/pkg/uuid"
+)
+
+// plan collects candidate entity sets and keeps the most selective one
+type plan struct {
+	candidates map[string]bool
+	indexed    bool
+}
+
+// consider makes set the plan candidates if it is more selective than the current ones
+func (p *plan) consider(set map[string]bool) {
+	if !p.indexed || len(set) < len(p.candidates) {
+		p.candidates = set
+		p.indexed = true
+	}
+}
+
+// result returns the plan candidates.
+// It returns nil if no index has been considered.
+func (p *plan) result() map[string]bool {
+	if !p.indexed {
+		return nil
+	}
+
+	if p.candidates == nil {
+		return make(map[string]bool)
+	}
+
+	return p.candidates
+}
+
+// considerAttrs considers the attribute index lookup if m is an equality attrs matcher
+func (p *plan) considerAttrs(idx attrIndex, m query.Matcher) {
+	if !m.IsEq() {
+		return
+	}
+
+	if a, ok := m.Value().(attrs.Attrs); ok && len(a.Keys()) > 0 {
+		p.consider(idx.lookup(a))
+	}
+}
+
+// planNodes returns the UIDs of the nodes which can match the query.
+// It uses the most selective index among the equality matchers of the query.
+// It returns nil if no index can be used and all the nodes need to be scanned.
+func (m *Memory) planNodes(match *query.Match) (map[string]bool, error) {
+	p := &plan{}
+
+	if um := match.UID(); um.IsEq() {
+		uid, ok := um.Value().(uuid.UID)
+		if !ok {
+			return nil, query.ErrInvalidUID
+		}
+
+		set := make(map[string]bool)
+		if _, ok := m.nodes[uid.String()]; ok {
+			set[uid.String()] = true
+		}
+
+		return set, nil
+	}
+
+	for _, c := range []struct {
+		val interface{}
+		eq  bool
+		idx index
+		err error
+	}{
+		{match.Namespace().Value(), match.Namespace().IsEq(), m.nindex.ns, query.ErrInvalidNamespace},
+		{match.Kind().Value(), match.Kind().IsEq(), m.nindex.kind, query.ErrInvalidKind},
+		{match.Name().Value(), match.Name().IsEq(), m.nindex.name, query.ErrInvalidName},
+		{match.Source().Value(), match.Source().IsEq(), m.nindex.source, query.ErrInvalidSource},
+	} {
+		if !c.eq {
+			continue
+		}
+
+		val, ok := c.val.(string)
+		if !ok {
+			return nil, c.err
+		}
+
+		p.consider(c.idx[val])
+	}
+
+	p.considerAttrs(m.nindex.attrs, match.Attrs())
+
+	return p.result(), nil
+}
+
+// planLines returns the UIDs of the lines which can match the query.
+// It uses the most selective index among the equality matchers of the query.
+// It returns nil if no index can be used and all the lines need to be scanned.
+func (m *Memory) planLines(match *query.Match) (map[string]bool, error) {
+	p := &plan{}
+
+	if um := match.UID(); um.IsEq() {
+		uid, ok := um.Value().(uuid.UID)
+		if !ok {
+			return nil, query.ErrInvalidUID
+		}
+
+		set := make(map[string]bool)
+		if _, ok := m.lines[uid.String()]; ok {
+			set[uid.String()] = true
+		}
+
+		return set, nil
+	}
+
+	p.considerAttrs(m.lindex.attrs, match.Attrs())
+
+	return p.result(), nil
+}
@@ -0,0 +1,613 @@
+package memory
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/milosgajdos/kraph/pkg/api"
+	"github.com/milosgajdos/kraph/pkg/attrs"
+	"github.com/milosgajdos/kraph/pkg/errors"
+	"github.com/milosgajdos/kraph/pkg/metadata"
+	"github.com/milosgajdos/kraph/pkg/query"
+	"github.com/milosgajdos/kraph/pkg/store"
+)
+
+// Tx is memory store transaction.
+// All the writes are staged and applied to the store on Commit.
+// The transaction sees the store as it was at the start of the
+// transaction along with the changes made by the staged writes.
+type Tx struct {
+	// mu guards the transaction state
+	mu sync.Mutex
+	// m is the transaction store
+	m *Memory
+	// version is the store version at the start of the transaction
+	version uint64
+	// nodes are the nodes added or updated in the transaction
+	nodes map[string]*Node
+	// lines are the lines linked or updated in the transaction
+	lines map[string]*Line
+	// edges maps the nodes and relation of the lines linked in the transaction to their UIDs
+	edges map[string]string
+	// delNodes are the UIDs of the nodes deleted in the transaction
+	delNodes map[string]bool
+	// delLines are the UIDs of the lines deleted in the transaction
+	delLines map[string]bool
+	// writes are the staged writes applied to the store on Commit
+	writes []func(*Memory) error
+	// done is true once the transaction has been committed or rolled back
+	done bool
+}
+
+// Begin starts a new transaction and returns it.
+// Commit fails with errors.ErrTxConflict if the store
+// has been modified since the transaction started.
+func (m *Memory) Begin() (store.Tx, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return &Tx{
+		m:        m,
+		version:  m.version,
+		nodes:    make(map[string]*Node),
+		lines:    make(map[string]*Line),
+		edges:    make(map[string]string),
+		delNodes: make(map[string]bool),
+		delLines: make(map[string]bool),
+	}, nil
+}
+
+// node returns the staged or stored node with the given uid.
+// It must be called with the transaction and store locks held.
+func (t *Tx) node(uid string) (*Node, bool) {
+	if n, ok := t.nodes[uid]; ok {
+		return n, true
+	}
+
+	if t.delNodes[uid] {
+		return nil, false
+	}
+
+	n, ok := t.m.nodes[uid]
+
+	return n, ok
+}
+
+// line returns the staged or stored line with the given uid.
+// It must be called with the transaction and store locks held.
+func (t *Tx) line(uid string) (*Line, bool) {
+	if l, ok := t.lines[uid]; ok {
+		return l, true
+	}
+
+	if t.delLines[uid] {
+		return nil, false
+	}
+
+	l, ok := t.m.lines[uid]
+
+	return l, ok
+}
+
+// linesOf returns the staged or stored lines of node in the given direction sorted by their UIDs.
+// It must be called with the transaction and store locks held.
+func (t *Tx) linesOf(node *Node, dir store.Direction) []*Line {
+	var lines []*Line
+
+	if n, ok := t.m.nodes[node.UID()]; ok {
+		for _, l := range t.m.linesOf(n, dir) {
+			if l, ok := t.line(l.UID()); ok {
+				lines = append(lines, l)
+			}
+		}
+	}
+
+	for uid, l := range t.lines {
+		if _, ok := t.m.lines[uid]; ok {
+			continue
+		}
+
+		from, to := l.from.UID() == node.UID(), l.to.UID() == node.UID()
+
+		switch {
+		case dir == store.DirOut && from,
+			dir == store.DirIn && to,
+			dir == store.DirBoth && (from || to):
+			lines = append(lines, l)
+		}
+	}
+
+	sort.Slice(lines, func(i, j int) bool {
+		return lines[i].UID() < lines[j].UID()
+	})
+
+	return lines
+}
+
+// removeNodes stages removing nodes along with all their lines
+// and returns the removed entities.
+// It must be called with the transaction and store locks held.
+func (t *Tx) removeNodes(nodes []*Node) []store.Entity {
+	var removed []store.Entity
+
+	for _, node := range nodes {
+		for _, l := range t.linesOf(node, store.DirBoth) {
+			t.removeLine(l)
+			removed = append(removed, l.Edge)
+		}
+	}
+
+	for _, node := range nodes {
+		// cascaded nodes are looked up in the lines which may link stale node copies
+		if n, ok := t.node(node.UID()); ok {
+			node = n
+		}
+
+		delete(t.nodes, node.UID())
+		t.delNodes[node.UID()] = true
+		removed = append(removed, node)
+	}
+
+	return removed
+}
+
+// removeLine stages removing line l.
+// It must be called with the transaction lock held.
+func (t *Tx) removeLine(l *Line) {
+	delete(t.lines, l.UID())
+	t.delLines[l.UID()] = true
+}
+
+// queryNodes returns the staged or stored nodes matching q.
+// It must be called with the transaction and store locks held.
+func (t *Tx) queryNodes(q *query.Query) ([]*Node, error) {
+	match := q.Matcher()
+
+	candidates, err := t.m.planNodes(match)
+	if err != nil {
+		return nil, err
+	}
+
+	set := make(map[string]bool)
+	if candidates != nil {
+		for uid := range candidates {
+			set[uid] = true
+		}
+	} else {
+		for uid := range t.m.nodes {
+			set[uid] = true
+		}
+	}
+
+	// staged nodes are not indexed
+	for uid := range t.nodes {
+		set[uid] = true
+	}
+
+	var nodes []*Node
+
+	p := page{offset: match.Offset(), limit: match.Limit()}
+
+	for _, uid := range sortedKeys(set) {
+		if p.done() {
+			break
+		}
+
+		node, ok := t.node(uid)
+		if !ok {
+			continue
+		}
+
+		if !matchNode(match, node, node.Metadata().Get("object").(api.Object)) || !p.accept() {
+			continue
+		}
+
+		nodes = append(nodes, node)
+	}
+
+	return nodes, nil
+}
+
+// queryLines returns the staged or stored lines matching q.
+// It must be called with the transaction and store locks held.
+func (t *Tx) queryLines(q *query.Query) ([]*Line, error) {
+	match := q.Matcher()
+
+	candidates, err := t.m.planLines(match)
+	if err != nil {
+		return nil, err
+	}
+
+	set := make(map[string]bool)
+	if candidates != nil {
+		for uid := range candidates {
+			set[uid] = true
+		}
+	} else {
+		for uid := range t.m.lines {
+			set[uid] = true
+		}
+	}
+
+	// staged lines are not indexed
+	for uid := range t.lines {
+		set[uid] = true
+	}
+
+	var lines []*Line
+
+	p := page{offset: match.Offset(), limit: match.Limit()}
+
+	for _, uid := range sortedKeys(set) {
+		if p.done() {
+			break
+		}
+
+		l, ok := t.line(uid)
+		if !ok {
+			continue
+		}
+
+		if !matchLine(match, l) || !p.accept() {
+			continue
+		}
+
+		lines = append(lines, l)
+	}
+
+	return lines, nil
+}
+
+// Add stages adding obj to the store and returns it
+func (t *Tx) Add(obj api.Object, opts store.AddOptions) (store.Entity, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.done {
+		return nil, errors.ErrTxDone
+	}
+
+	uid := obj.UID().String()
+
+	t.m.mu.RLock()
+	n, ok := t.node(uid)
+	t.m.mu.RUnlock()
+
+	if ok {
+		return n, nil
+	}
+
+	if obj.Resource() == nil {
+		return nil, errors.ErrMissingResource
+	}
+
+	opts.Attrs = copyAttrs(opts.Attrs)
+	opts.Metadata = copyMetadata(opts.Metadata)
+
+	// staged nodes get their graph ID once they're added to the store
+	node := newNode(0, obj, opts)
+	t.nodes[uid] = node
+
+	t.writes = append(t.writes, func(m *Memory) error {
+		_, err := m.add(obj, opts)
+		return err
+	})
+
+	return node, nil
+}
+
+// Update stages updating entity e and returns it
+func (t *Tx) Update(e store.Entity, opts store.UpdateOptions) (store.Entity, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.done {
+		return nil, errors.ErrTxDone
+	}
+
+	t.m.mu.RLock()
+	defer t.m.mu.RUnlock()
+
+	opts.Attrs = copyAttrs(opts.Attrs)
+	opts.Metadata = copyMetadata(opts.Metadata)
+
+	// staged entities are not indexed
+	idx := make(attrIndex)
+
+	var ent store.Entity
+
+	switch v := e.(type) {
+	case store.Edge:
+		l, ok := t.line(v.UID())
+		if !ok {
+			return nil, fmt.Errorf("Edge Update %s: %w", v.UID(), errors.ErrEdgeNotFound)
+		}
+
+		line := copyLine(l, l.from, l.to)
+		update(line.Edge, opts, idx)
+		t.lines[line.UID()] = line
+
+		ent = line.Edge
+	case store.Node:
+		node, ok := t.node(v.UID())
+		if !ok {
+			return nil, fmt.Errorf("Node Update %s: %w", v.UID(), errors.ErrNodeNotFound)
+		}
+
+		n := copyNode(node, node.Metadata().Get("object").(api.Object))
+		update(n, opts, idx)
+		t.nodes[n.UID()] = n
+
+		ent = n
+	default:
+		return nil, errors.ErrUnknownEntity
+	}
+
+	t.writes = append(t.writes, func(m *Memory) error {
+		_, err := m.update(e, opts)
+		return err
+	})
+
+	return ent, nil
+}
+
+// Delete stages deleting entity e from the store
+func (t *Tx) Delete(e store.Entity, opts store.DelOptions) ([]store.Entity, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.done {
+		return nil, errors.ErrTxDone
+	}
+
+	t.m.mu.RLock()
+	defer t.m.mu.RUnlock()
+
+	var removed []store.Entity
+
+	switch v := e.(type) {
+	case store.Edge:
+		l, ok := t.line(v.UID())
+		if !ok {
+			return nil, fmt.Errorf("Edge Delete %s: %w", v.UID(), errors.ErrEdgeNotFound)
+		}
+
+		t.removeLine(l)
+		removed = append(removed, l.Edge)
+	case store.Node:
+		node, ok := t.node(v.UID())
+		if !ok {
+			return nil, fmt.Errorf("Node Delete %s: %w", v.UID(), errors.ErrNodeNotFound)
+		}
+
+		nodes, err := cascade([]*Node{node}, opts, t.linesOf)
+		if err != nil {
+			return nil, err
+		}
+
+		removed = t.removeNodes(nodes)
+	default:
+		return nil, errors.ErrUnknownEntity
+	}
+
+	t.writes = append(t.writes, func(m *Memory) error {
+		_, err := m.delete(e, opts)
+		return err
+	})
+
+	return removed, nil
+}
+
+// DeleteQuery stages deleting the entities matching q from the store
+func (t *Tx) DeleteQuery(q *query.Query, opts store.DelOptions) ([]store.Entity, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.done {
+		return nil, errors.ErrTxDone
+	}
+
+	e, err := queryEntity(q)
+	if err != nil {
+		return nil, err
+	}
+
+	t.m.mu.RLock()
+	defer t.m.mu.RUnlock()
+
+	var removed []store.Entity
+
+	switch e {
+	case query.Node:
+		nodes, err := t.queryNodes(q)
+		if err != nil {
+			return nil, fmt.Errorf("Node Delete: %w", err)
+		}
+
+		if nodes, err = cascade(nodes, opts, t.linesOf); err != nil {
+			return nil, err
+		}
+
+		removed = t.removeNodes(nodes)
+	case query.Edge:
+		lines, err := t.queryLines(q)
+		if err != nil {
+			return nil, fmt.Errorf("Edge Delete: %w", err)
+		}
+
+		for _, l := range lines {
+			t.removeLine(l)
+			removed = append(removed, l.Edge)
+		}
+	default:
+		return nil, errors.ErrUnknownEntity
+	}
+
+	if len(removed) > 0 {
+		t.writes = append(t.writes, func(m *Memory) error {
+			_, err := m.deleteQuery(q, opts)
+			return err
+		})
+	}
+
+	return removed, nil
+}
+
+// Link stages linking the nodes and returns the edge between them
+func (t *Tx) Link(from store.Node, to store.Node, opts store.LinkOptions) (store.Edge, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.done {
+		return nil, errors.ErrTxDone
+	}
+
+	t.m.mu.RLock()
+	defer t.m.mu.RUnlock()
+
+	f, ok := t.node(from.UID())
+	if !ok {
+		return nil, fmt.Errorf("Link %s: %w", from.UID(), errors.ErrNodeNotFound)
+	}
+
+	tn, ok := t.node(to.UID())
+	if !ok {
+		return nil, fmt.Errorf("Link %s: %w", to.UID(), errors.ErrNodeNotFound)
+	}
+
+	relation := linkRelation(opts)
+	key := edgeKey(f.UID(), tn.UID(), relation)
+
+	if !opts.Line {
+		if uid, ok := t.edges[key]; ok {
+			if l, ok := t.line(uid); ok {
+				return l.Edge, nil
+			}
+		}
+
+		bf, fok := t.m.nodes[f.UID()]
+		bt, tok := t.m.nodes[tn.UID()]
+		if fok && tok {
+			if l := t.m.line(bf, bt, relation); l != nil {
+				// the stored line may have been updated or deleted in the transaction
+				if l, ok := t.line(l.UID()); ok {
+					return l.Edge, nil
+				}
+			}
+		}
+	}
+
+	opts.Attrs = copyAttrs(opts.Attrs)
+	opts.Metadata = copyMetadata(opts.Metadata)
+
+	uid := uuid.New().String()
+	entOpts, _ := lineOptions(opts)
+	line := NewLine(0, uid, uid, f, tn, entOpts...)
+
+	t.lines[uid] = line
+	if _, ok := t.edges[key]; !ok || !opts.Line {
+		t.edges[key] = uid
+	}
+
+	fuid, tuid := f.UID(), tn.UID()
+
+	t.writes = append(t.writes, func(m *Memory) error {
+		f, ok := m.nodes[fuid]
+		if !ok {
+			return fmt.Errorf("Link %s: %w", fuid, errors.ErrNodeNotFound)
+		}
+
+		t, ok := m.nodes[tuid]
+		if !ok {
+			return fmt.Errorf("Link %s: %w", tuid, errors.ErrNodeNotFound)
+		}
+
+		m.link(f, t, opts, uid)
+
+		return nil
+	})
+
+	return line.Edge, nil
+}
+
+// Commit atomically applies all the staged writes to the store.
+func (t *Tx) Commit() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.done {
+		return errors.ErrTxDone
+	}
+	t.done = true
+
+	t.m.mu.Lock()
+	defer t.m.mu.Unlock()
+
+	if t.m.version != t.version {
+		return errors.ErrTxConflict
+	}
+
+	// the writes were validated against the store at this version
+	// so applying them to the unmodified store can not fail
+	for _, write := range t.writes {
+		if err := write(t.m); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Rollback discards all the staged writes.
+func (t *Tx) Rollback() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.done {
+		return errors.ErrTxDone
+	}
+	t.done = true
+	t.nodes = nil
+	t.lines = nil
+	t.edges = nil
+	t.delNodes = nil
+	t.delLines = nil
+	t.writes = nil
+
+	return nil
+}
+
+// edgeKey returns the key of the staged edge from node from to node to in the given relation
+func edgeKey(from, to, relation string) string {
+	return from + "\x00" + to + "\x00" + relation
+}
+
+// copyAttrs returns a copy of a
+func copyAttrs(a attrs.Attrs) attrs.Attrs {
+	if a == nil {
+		return nil
+	}
+
+	c := attrs.New()
+	for _, k := range a.Keys() {
+		c.Set(k, a.Get(k))
+	}
+
+	return c
+}
+
+// copyMetadata returns a copy of md
+func copyMetadata(md metadata.Metadata) metadata.Metadata {
+	if md == nil {
+		return nil
+	}
+
+	c := metadata.New()
+	for _, k := range md.Keys() {
+		c.Set(k, md.Get(k))
+	}
+
+	return c
+}
@@ -0,0 +1,335 @@
+package memory
+
+import (
+	goerr "errors"
+	"testing"
+
+	"github.com/milosgajdos/kraph/pkg/attrs"
+	"github.com/milosgajdos/kraph/pkg/errors"
+	"github.com/milosgajdos/kraph/pkg/query"
+	"github.com/milosgajdos/kraph/pkg/store"
+)
+
+func TestTxCommit(t *testing.T) {
+	m, err := newTestMemory()
+	if err != nil {
+		t.Fatalf("failed to create new memory store: %v", err)
+	}
+
+	nodes, err := m.Nodes()
+	if err != nil {
+		t.Fatalf("failed to get nodes: %v", err)
+	}
+	count := len(nodes)
+
+	tx, err := m.Begin()
+	if err != nil {
+		t.Fatalf("failed to begin transaction: %v", err)
+	}
+
+	node, err := tx.Add(newMockObject("txUID", "txName", "txNs"), store.NewAddOptions())
+	if err != nil {
+		t.Fatalf("failed to add node: %v", err)
+	}
+
+	root, err := m.Node("fooNs/fooKind/foo1")
+	if err != nil {
+		t.Fatalf("failed to get node: %v", err)
+	}
+
+	if _, err := tx.Link(root, node, store.NewLinkOptions()); err != nil {
+		t.Fatalf("failed to link nodes: %v", err)
+	}
+
+	del, err := m.Node("fooNs/fooKind/foo4")
+	if err != nil {
+		t.Fatalf("failed to get node: %v", err)
+	}
+
+	if _, err := tx.Delete(del, store.NewDelOptions()); err != nil {
+		t.Fatalf("failed to delete node: %v", err)
+	}
+
+	// staged writes must not be visible before commit
+	if _, err := m.Node("txUID"); !goerr.Is(err, errors.ErrNodeNotFound) {
+		t.Errorf("expected error: %v, got: %v", errors.ErrNodeNotFound, err)
+	}
+
+	if _, err := m.Node("fooNs/fooKind/foo4"); err != nil {
+		t.Errorf("failed to get node: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("failed to commit transaction: %v", err)
+	}
+
+	nodes, err = m.Nodes()
+	if err != nil {
+		t.Fatalf("failed to get nodes: %v", err)
+	}
+
+	if len(nodes) != count {
+		t.Errorf("expected nodes: %d, got: %d", count, len(nodes))
+	}
+
+	if _, err := m.Edges(root.UID(), "txUID"); err != nil {
+		t.Errorf("failed to get edges: %v", err)
+	}
+
+	if _, err := m.Node("fooNs/fooKind/foo4"); !goerr.Is(err, errors.ErrNodeNotFound) {
+		t.Errorf("expected error: %v, got: %v", errors.ErrNodeNotFound, err)
+	}
+
+	res, err := m.Query(query.Build().Entity(query.Node).Namespace("txNs"))
+	if err != nil {
+		t.Fatalf("failed to query nodes: %v", err)
+	}
+
+	if len(res) != 1 {
+		t.Errorf("expected nodes: %d, got: %d", 1, len(res))
+	}
+}
+
+func TestTxRollback(t *testing.T) {
+	m, err := newTestMemory()
+	if err != nil {
+		t.Fatalf("failed to create new memory store: %v", err)
+	}
+
+	tx, err := m.Begin()
+	if err != nil {
+		t.Fatalf("failed to begin transaction: %v", err)
+	}
+
+	if _, err := tx.Add(newMockObject("txUID", "txName", "txNs"), store.NewAddOptions()); err != nil {
+		t.Fatalf("failed to add node: %v", err)
+	}
+
+	if err := tx.Rollback(); err != nil {
+		t.Fatalf("failed to roll back transaction: %v", err)
+	}
+
+	if _, err := m.Node("txUID"); !goerr.Is(err, errors.ErrNodeNotFound) {
+		t.Errorf("expected error: %v, got: %v", errors.ErrNodeNotFound, err)
+	}
+
+	if _, err := tx.Add(newMockObject("txUID", "txName", "txNs"), store.NewAddOptions()); !goerr.Is(err, errors.ErrTxDone) {
+		t.Errorf("expected error: %v, got: %v", errors.ErrTxDone, err)
+	}
+
+	if err := tx.Commit(); !goerr.Is(err, errors.ErrTxDone) {
+		t.Errorf("expected error: %v, got: %v", errors.ErrTxDone, err)
+	}
+
+	if err := tx.Rollback(); !goerr.Is(err, errors.ErrTxDone) {
+		t.Errorf("expected error: %v, got: %v", errors.ErrTxDone, err)
+	}
+}
+
+func TestTxConflict(t *testing.T) {
+	m, err := newTestMemory()
+	if err != nil {
+		t.Fatalf("failed to create new memory store: %v", err)
+	}
+
+	tx, err := m.Begin()
+	if err != nil {
+		t.Fatalf("failed to begin transaction: %v", err)
+	}
+
+	if _, err := tx.Add(newMockObject("txUID", "txName", "txNs"), store.NewAddOptions()); err != nil {
+		t.Fatalf("failed to add node: %v", err)
+	}
+
+	if _, err := m.Add(newMockObject("fooUID", "fooName", "fooNs"), store.NewAddOptions()); err != nil {
+		t.Fatalf("failed to add node: %v", err)
+	}
+
+	if err := tx.Commit(); !goerr.Is(err, errors.ErrTxConflict) {
+		t.Errorf("expected error: %v, got: %v", errors.ErrTxConflict, err)
+	}
+
+	if _, err := m.Node("txUID"); !goerr.Is(err, errors.ErrNodeNotFound) {
+		t.Errorf("expected error: %v, got: %v", errors.ErrNodeNotFound, err)
+	}
+
+	if _, err := m.Node("fooUID"); err != nil {
+		t.Errorf("failed to get node: %v", err)
+	}
+}
+
+func TestTxStagedWrites(t *testing.T) {
+	m, err := newTestMemory()
+	if err != nil {
+		t.Fatalf("failed to create new memory store: %v", err)
+	}
+
+	tx, err := m.Begin()
+	if err != nil {
+		t.Fatalf("failed to begin transaction: %v", err)
+	}
+
+	node, err := tx.Add(newMockObject("txUID", "txName", "txNs"), store.NewAddOptions())
+	if err != nil {
+		t.Fatalf("failed to add node: %v", err)
+	}
+
+	if _, err := tx.Link(node, NewNode(0, "barUID", "barUID"), store.NewLinkOptions()); !goerr.Is(err, errors.ErrNodeNotFound) {
+		t.Errorf("expected error: %v, got: %v", errors.ErrNodeNotFound, err)
+	}
+
+	root, err := m.Node("fooNs/fooKind/foo1")
+	if err != nil {
+		t.Fatalf("failed to get node: %v", err)
+	}
+
+	opts := store.NewLinkOptions()
+	opts.Relation = "txRel"
+
+	edge, err := tx.Link(root, node, opts)
+	if err != nil {
+		t.Fatalf("failed to link nodes: %v", err)
+	}
+
+	dup, err := tx.Link(root, node, opts)
+	if err != nil {
+		t.Fatalf("failed to link nodes: %v", err)
+	}
+
+	if dup.UID() != edge.UID() {
+		t.Errorf("expected edge: %s, got: %s", edge.UID(), dup.UID())
+	}
+
+	rev, err := tx.Link(node, root, opts)
+	if err != nil {
+		t.Fatalf("failed to link nodes: %v", err)
+	}
+
+	if rev.UID() == edge.UID() {
+		t.Errorf("expected reverse edge, got: %s", rev.UID())
+	}
+
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("failed to commit transaction: %v", err)
+	}
+
+	edges, err := m.Edges(root.UID(), "txUID")
+	if err != nil {
+		t.Fatalf("failed to get edges: %v", err)
+	}
+
+	if len(edges) != 2 {
+		t.Fatalf("expected edges: %d, got: %d", 2, len(edges))
+	}
+
+	for _, e := range edges {
+		if e.UID() != edge.UID() && e.UID() != rev.UID() {
+			t.Errorf("unexpected edge: %s", e.UID())
+		}
+
+		if rel := e.Attrs().Get("relation"); rel != "txRel" {
+			t.Errorf("expected relation: %s, got: %s", "txRel", rel)
+		}
+	}
+}
+
+func TestTxStagedUpdatesDeletes(t *testing.T) {
+	m, err := newTestMemory()
+	if err != nil {
+		t.Fatalf("failed to create new memory store: %v", err)
+	}
+
+	tx, err := m.Begin()
+	if err != nil {
+		t.Fatalf("failed to begin transaction: %v", err)
+	}
+
+	root, err := m.Node("fooNs/fooKind/foo1")
+	if err != nil {
+		t.Fatalf("failed to get node: %v", err)
+	}
+
+	node, err := tx.Add(newMockObject("txUID", "txName", "txNs"), store.NewAddOptions())
+	if err != nil {
+		t.Fatalf("failed to add node: %v", err)
+	}
+
+	edge, err := tx.Link(root, node, store.NewLinkOptions())
+	if err != nil {
+		t.Fatalf("failed to link nodes: %v", err)
+	}
+
+	opts := store.NewUpdateOptions()
+	opts.Attrs.Set("txKey", "txVal")
+
+	for _, e := range []store.Entity{root, edge} {
+		up, err := tx.Update(e, opts)
+		if err != nil {
+			t.Fatalf("failed to update %s: %v", e.UID(), err)
+		}
+
+		if val := up.Attrs().Get("txKey"); val != "txVal" {
+			t.Errorf("%s: expected attribute: %s, got: %s", e.UID(), "txVal", val)
+		}
+	}
+
+	// staged edges prevent deleting the linked nodes
+	delOpts := store.NewDelOptions()
+	delOpts.Cascade = store.CascadeNone
+
+	if _, err := tx.Delete(node, delOpts); !goerr.Is(err, errors.ErrNodeHasEdges) {
+		t.Errorf("expected error: %v, got: %v", errors.ErrNodeHasEdges, err)
+	}
+
+	// updated staged edges are matched by queries
+	a := attrs.New()
+	a.Set("txKey", "txVal")
+
+	removed, err := tx.DeleteQuery(query.Build().Entity(query.Edge).Attrs(a), store.NewDelOptions())
+	if err != nil {
+		t.Fatalf("failed to delete edges: %v", err)
+	}
+
+	if len(removed) != 1 || removed[0].UID() != edge.UID() {
+		t.Errorf("expected removed edge: %s, got: %v", edge.UID(), removed)
+	}
+
+	if _, err := tx.Delete(node, delOpts); err != nil {
+		t.Errorf("failed to delete node: %v", err)
+	}
+
+	if _, err := tx.Update(node, opts); !goerr.Is(err, errors.ErrNodeNotFound) {
+		t.Errorf("expected error: %v, got: %v", errors.ErrNodeNotFound, err)
+	}
+
+	// staged writes must not be visible before commit
+	if n, err := m.Node(root.UID()); err != nil || n.Attrs().Get("txKey") != "" {
+		t.Errorf("expected stored node to be unchanged, got: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("failed to commit transaction: %v", err)
+	}
+
+	n, err := m.Node(root.UID())
+	if err != nil {
+		t.Fatalf("failed to get node: %v", err)
+	}
+
+	if val := n.Attrs().Get("txKey"); val != "txVal" {
+		t.Errorf("expected attribute: %s, got: %s", "txVal", val)
+	}
+
+	if _, err := m.Node("txUID"); !goerr.Is(err, errors.ErrNodeNotFound) {
+		t.Errorf("expected error: %v, got: %v", errors.ErrNodeNotFound, err)
+	}
+
+	res, err := m.Query(query.Build().Entity(query.Edge).Attrs(a))
+	if err != nil {
+		t.Fatalf("failed to query edges: %v", err)
+	}
+
+	if len(res) != 0 {
+		t.Errorf("expected edges: %d, got: %d", 0, len(res))
+	}
+}
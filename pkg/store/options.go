@@ -3,11 +3,27 @@ package store
 import (
 	"github.com/milosgajdos/kraph/pkg/attrs"
 	"github.com/milosgajdos/kraph/pkg/metadata"
+	"github.com/milosgajdos/kraph/pkg/relation"
 )
 
 const (
 	// DefaultWeight is default weight
 	DefaultWeight = 1.0
+	// DefaultOwnRelation is the default relation of the owned objects to their owners
+	DefaultOwnRelation = "isOwned"
+)
+
+// Cascade configures which entities are deleted along with a node
+type Cascade int
+
+const (
+	// CascadeEdges deletes the node along with all its edges
+	CascadeEdges Cascade = iota
+	// CascadeNone refuses to delete a node which has edges
+	CascadeNone
+	// CascadeOwned deletes the node along with its edges
+	// and all the nodes it owns, recursively
+	CascadeOwned
 )
 
 // DOTOptions are DOT options
@@ -15,6 +31,10 @@ type DOTOptions struct {
 	GraphAttrs attrs.DOT
 	NodeAttrs  attrs.DOT
 	EdgeAttrs  attrs.DOT
+	// Relations styles the edges of the registered relations.
+	// The registry is consulted on every export, so the changes
+	// of the registry apply to the graphs which are already built.
+	Relations *relation.Registry
 }
 
 // DOTOption configures store
@@ -53,6 +73,10 @@ func NewAddOptions() AddOptions {
 type DelOptions struct {
 	Attrs    attrs.Attrs
 	Metadata metadata.Metadata
+	// Cascade is the node delete cascade mode
+	Cascade Cascade
+	// OwnRelation is the relation of the owned nodes to their owners
+	OwnRelation string
 }
 
 // DelOption sets options
@@ -61,11 +85,58 @@ type DelOption func(*DelOptions)
 // NewDelOptions returns default del options
 func NewDelOptions() DelOptions {
 	return DelOptions{
+		Attrs:       attrs.New(),
+		Metadata:    metadata.New(),
+		Cascade:     CascadeEdges,
+		OwnRelation: DefaultOwnRelation,
+	}
+}
+
+// UpdateOptions are update options
+type UpdateOptions struct {
+	Attrs    attrs.Attrs
+	Metadata metadata.Metadata
+}
+
+// UpdateOption sets options
+type UpdateOption func(*UpdateOptions)
+
+// NewUpdateOptions returns default update options
+func NewUpdateOptions() UpdateOptions {
+	return UpdateOptions{
 		Attrs:    attrs.New(),
 		Metadata: metadata.New(),
 	}
 }
 
+// SubGraphOptions are subgraph options
+type SubGraphOptions struct {
+	// Depth is the maximum distance of the subgraph nodes from the root node
+	Depth int
+	// Direction is the direction of the traversed edges
+	Direction Direction
+	// Relations limits the traversal to the edges of the given relations.
+	// All the edges are traversed if no relations are given.
+	Relations []string
+	// NodeFilter limits the traversal to the nodes for which it returns true.
+	// All the nodes are traversed if it is nil.
+	NodeFilter func(Node) bool
+	// MaxNodes limits the number of subgraph nodes.
+	// Zero means the number of nodes is not limited.
+	MaxNodes int
+}
+
+// SubGraphOption sets options
+type SubGraphOption func(*SubGraphOptions)
+
+// NewSubGraphOptions returns default subgraph options
+func NewSubGraphOptions() SubGraphOptions {
+	return SubGraphOptions{
+		Depth:     1,
+		Direction: DirBoth,
+	}
+}
+
 // LinkOptions are link options
 type LinkOptions struct {
 	Line     bool
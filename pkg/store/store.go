@@ -8,6 +8,18 @@ import (
 	"gonum.org/v1/gonum/graph/encoding"
 )
 
+// Direction is the direction of edges relative to a node
+type Direction int
+
+const (
+	// DirBoth matches both outgoing and incoming edges
+	DirBoth Direction = iota
+	// DirOut matches the edges from the node
+	DirOut
+	// DirIn matches the edges to the node
+	DirIn
+)
+
 // Entity is store entity
 type Entity interface {
 	// UID returns unique ID
@@ -52,6 +64,32 @@ type Edge interface {
 	Weight() float64
 }
 
+// NodeIterator iterates over store nodes
+type NodeIterator interface {
+	// Next advances the iterator to the next node.
+	// It returns false when there are no more nodes or on error.
+	Next() bool
+	// Node returns the current node
+	Node() Node
+	// Err returns the iteration error, if any
+	Err() error
+	// Close releases the iterator resources
+	Close() error
+}
+
+// EdgeIterator iterates over store edges
+type EdgeIterator interface {
+	// Next advances the iterator to the next edge.
+	// It returns false when there are no more edges or on error.
+	Next() bool
+	// Edge returns the current edge
+	Edge() Edge
+	// Err returns the iteration error, if any
+	Err() error
+	// Close releases the iterator resources
+	Close() error
+}
+
 // DOTGraph returns Graphiz DOT store
 type DOTGraph interface {
 	Graph
@@ -70,23 +108,65 @@ type Graph interface {
 	Node(id string) (Node, error)
 	// Nodes returns all the nodes in the graph.
 	Nodes() ([]Node, error)
+	// IterNodes returns an iterator over all the nodes in the graph.
+	IterNodes() (NodeIterator, error)
 	// Edges returns all the edges between the nodes vid and uid
 	Edges(uid, vid string) ([]Edge, error)
+	// AllEdges returns an iterator over all the edges in the graph.
+	AllEdges() (EdgeIterator, error)
+	// EdgesOf returns all the edges of the node in the given direction.
+	EdgesOf(Node, Direction) ([]Edge, error)
+	// Neighbours returns all the nodes linked to the node.
+	// If any relations are given only the nodes linked
+	// via at least one of the relations are returned.
+	Neighbours(Node, ...string) ([]Node, error)
+	// Degree returns the number of the edges of the node in the given direction.
+	Degree(Node, Direction) (int, error)
 	// Link links two nodes and returns the new edge between them
 	// or it returns error if the link couldn't be created.
 	Link(Node, Node, LinkOptions) (Edge, error)
 	// SubGraph returns a subgraph of the graph starting at Node
-	// up to the given depth or it returns error.
-	SubGraph(Node, int) (Graph, error)
+	// traversed according to the given options or it returns error.
+	SubGraph(Node, SubGraphOptions) (Graph, error)
+}
+
+// Tx is a store transaction.
+// The writes staged in the transaction are applied to the store
+// atomically on Commit or discarded on Rollback.
+type Tx interface {
+	// Add stages adding an api.Object to the store and returns it
+	Add(api.Object, AddOptions) (Entity, error)
+	// Update stages updating entity attributes and metadata and returns it
+	Update(Entity, UpdateOptions) (Entity, error)
+	// Delete stages deleting an entity from the store and returns the removed entities
+	Delete(Entity, DelOptions) ([]Entity, error)
+	// DeleteQuery stages deleting the entities matching the query and returns the removed entities
+	DeleteQuery(*query.Query, DelOptions) ([]Entity, error)
+	// Link stages linking two nodes and returns the new edge between them
+	Link(Node, Node, LinkOptions) (Edge, error)
+	// Commit applies all the staged writes to the store
+	Commit() error
+	// Rollback discards all the staged writes
+	Rollback() error
 }
 
 // Store allows to store and query the graph of API objects
 type Store interface {
 	Graph
+	// Begin starts a new transaction
+	Begin() (Tx, error)
 	// Add adds an api.Object to the store and returns it
 	Add(api.Object, AddOptions) (Entity, error)
-	// Delete deletes an entity from the store
-	Delete(Entity, DelOptions) error
+	// Update updates entity attributes and metadata and returns it
+	Update(Entity, UpdateOptions) (Entity, error)
+	// Delete deletes an entity from the store and returns the removed entities
+	Delete(Entity, DelOptions) ([]Entity, error)
+	// DeleteQuery deletes the entities matching the query and returns the removed entities
+	DeleteQuery(*query.Query, DelOptions) ([]Entity, error)
 	// Query queries the store and returns the results
 	Query(*query.Query) ([]Entity, error)
+	// QueryNodes queries the store nodes and returns an iterator over the results
+	QueryNodes(*query.Query) (NodeIterator, error)
+	// QueryEdges queries the store edges and returns an iterator over the results
+	QueryEdges(*query.Query) (EdgeIterator, error)
 }
@@ -0,0 +1,195 @@
+package storetest
+
+import (
+	goerr "errors"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/milosgajdos/kraph/pkg/api/gen"
+	"github.com/milosgajdos/kraph/pkg/errors"
+	"github.com/milosgajdos/kraph/pkg/store"
+)
+
+// link is a test graph link
+type link struct {
+	from     string
+	to       string
+	relation string
+}
+
+var (
+	// uids are the UIDs of the test graph nodes
+	uids = []string{"a", "b", "c", "d"}
+	// links are the test graph links; d is not linked
+	links = []link{
+		{"a", "b", "r1"},
+		{"a", "b", "r2"},
+		{"a", "c", "r2"},
+		{"c", "a", "r1"},
+		// reverse edge of the same relation is a distinct edge
+		{"b", "a", "r1"},
+	}
+)
+
+// NewStoreFunc returns a new empty store
+type NewStoreFunc func() (store.Store, error)
+
+// newGraph returns a new store with the test graph and its nodes.
+func newGraph(t *testing.T, newStore NewStoreFunc) (store.Store, map[string]store.Node) {
+	s, err := newStore()
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+
+	res := gen.NewResource("res", "fooKind", "fooGroup", "v1", true)
+
+	nodes := make(map[string]store.Node)
+	for _, uid := range uids {
+		e, err := s.Add(gen.NewMockObject(uid, uid, "fooNs", res), store.NewAddOptions())
+		if err != nil {
+			t.Fatalf("failed to add node %s: %v", uid, err)
+		}
+		nodes[uid] = e.(store.Node)
+	}
+
+	for _, l := range links {
+		opts := store.NewLinkOptions()
+		opts.Relation = l.relation
+
+		if _, err := s.Link(nodes[l.from], nodes[l.to], opts); err != nil {
+			t.Fatalf("failed to link %s to %s: %v", l.from, l.to, err)
+		}
+	}
+
+	return s, nodes
+}
+
+// missingNode returns a node which has been deleted from store s
+func missingNode(t *testing.T, s store.Store) store.Node {
+	res := gen.NewResource("res", "fooKind", "fooGroup", "v1", true)
+
+	e, err := s.Add(gen.NewMockObject("missing", "missing", "fooNs", res), store.NewAddOptions())
+	if err != nil {
+		t.Fatalf("failed to add node: %v", err)
+	}
+
+	if _, err := s.Delete(e, store.NewDelOptions()); err != nil {
+		t.Fatalf("failed to delete node: %v", err)
+	}
+
+	return e.(store.Node)
+}
+
+// TestGraph runs the store.Graph conformance tests
+// against the stores returned by newStore.
+func TestGraph(t *testing.T, newStore NewStoreFunc) {
+	t.Run("EdgesOf", func(t *testing.T) { testEdgesOf(t, newStore) })
+	t.Run("Degree", func(t *testing.T) { testDegree(t, newStore) })
+	t.Run("Neighbours", func(t *testing.T) { testNeighbours(t, newStore) })
+}
+
+var dirTests = []struct {
+	uid string
+	dir store.Direction
+	exp int
+}{
+	{"a", store.DirBoth, 5},
+	{"a", store.DirOut, 3},
+	{"a", store.DirIn, 2},
+	{"b", store.DirBoth, 3},
+	{"b", store.DirOut, 1},
+	{"b", store.DirIn, 2},
+	{"c", store.DirOut, 1},
+	{"c", store.DirIn, 1},
+	{"d", store.DirBoth, 0},
+}
+
+func testEdgesOf(t *testing.T, newStore NewStoreFunc) {
+	s, nodes := newGraph(t, newStore)
+
+	for _, test := range dirTests {
+		edges, err := s.EdgesOf(nodes[test.uid], test.dir)
+		if err != nil {
+			t.Fatalf("failed to get edges of %s: %v", test.uid, err)
+		}
+
+		if len(edges) != test.exp {
+			t.Errorf("%s %d: expected edges: %d, got: %d", test.uid, test.dir, test.exp, len(edges))
+		}
+
+		for _, e := range edges {
+			from, to := e.From().UID(), e.To().UID()
+
+			switch {
+			case from != test.uid && to != test.uid:
+				t.Errorf("%s: edge not adjacent: %s -> %s", test.uid, from, to)
+			case test.dir == store.DirOut && from != test.uid,
+				test.dir == store.DirIn && to != test.uid:
+				t.Errorf("%s %d: unexpected edge direction: %s -> %s", test.uid, test.dir, from, to)
+			}
+		}
+	}
+
+	if _, err := s.EdgesOf(missingNode(t, s), store.DirBoth); !goerr.Is(err, errors.ErrNodeNotFound) {
+		t.Errorf("expected error: %v, got: %v", errors.ErrNodeNotFound, err)
+	}
+}
+
+func testDegree(t *testing.T, newStore NewStoreFunc) {
+	s, nodes := newGraph(t, newStore)
+
+	for _, test := range dirTests {
+		degree, err := s.Degree(nodes[test.uid], test.dir)
+		if err != nil {
+			t.Fatalf("failed to get degree of %s: %v", test.uid, err)
+		}
+
+		if degree != test.exp {
+			t.Errorf("%s %d: expected degree: %d, got: %d", test.uid, test.dir, test.exp, degree)
+		}
+	}
+
+	if _, err := s.Degree(missingNode(t, s), store.DirBoth); !goerr.Is(err, errors.ErrNodeNotFound) {
+		t.Errorf("expected error: %v, got: %v", errors.ErrNodeNotFound, err)
+	}
+}
+
+func testNeighbours(t *testing.T, newStore NewStoreFunc) {
+	s, nodes := newGraph(t, newStore)
+
+	tests := []struct {
+		uid       string
+		relations []string
+		exp       []string
+	}{
+		{"a", nil, []string{"b", "c"}},
+		{"a", []string{"r1"}, []string{"b", "c"}},
+		{"b", []string{"r2"}, []string{"a"}},
+		{"b", []string{"r1"}, []string{"a"}},
+		{"c", []string{"r1", "r2"}, []string{"a"}},
+		{"a", []string{"nonEx"}, nil},
+		{"d", nil, nil},
+	}
+
+	for _, test := range tests {
+		neighbours, err := s.Neighbours(nodes[test.uid], test.relations...)
+		if err != nil {
+			t.Fatalf("failed to get neighbours of %s: %v", test.uid, err)
+		}
+
+		var uids []string
+		for _, n := range neighbours {
+			uids = append(uids, n.UID())
+		}
+		sort.Strings(uids)
+
+		if !reflect.DeepEqual(uids, test.exp) {
+			t.Errorf("%s %v: expected neighbours: %v, got: %v", test.uid, test.relations, test.exp, uids)
+		}
+	}
+
+	if _, err := s.Neighbours(missingNode(t, s)); !goerr.Is(err, errors.ErrNodeNotFound) {
+		t.Errorf("expected error: %v, got: %v", errors.ErrNodeNotFound, err)
+	}
+}